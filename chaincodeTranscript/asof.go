@@ -0,0 +1,256 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Point-in-time transcripts: the records of a student rebuilt from the history of their keys
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// GetStudentTranscriptAsOf constructs the transcript of a student as it was at the given moment, given in RFC3339 or as a date (YYYY-MM-DD,
+// meaning the end of that day in UTC). The MetaInfo records, the records they index, the course offerings, the HEI configuration and its grading
+// scales are read as they were then.
+func (Transcript *SmartContract) GetStudentTranscriptAsOf(ctx contractapi.TransactionContextInterface, hei string, studentID string, timestamp string) (*StudentTranscript, error) {
+	asOf, err := parseAsOf(timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	return Transcript.transcriptAsOf(ctx, hei, studentID, ledgerSnapshot{asOf: asOf})
+}
+
+// stateReader reads the world state either as it is now or as it was at a moment
+type stateReader interface {
+	getState(ctx contractapi.TransactionContextInterface, key string) ([]byte, error)
+	getByPartialCompositeKey(ctx contractapi.TransactionContextInterface, objectType string, attributes []string, handle func(value []byte) error) error
+}
+
+// currentState reads the world state as it is now
+type currentState struct{}
+
+func (currentState) getState(ctx contractapi.TransactionContextInterface, key string) ([]byte, error) {
+	jsonData, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	return jsonData, nil
+}
+
+func (currentState) getByPartialCompositeKey(ctx contractapi.TransactionContextInterface, objectType string, attributes []string, handle func(value []byte) error) error {
+	return getByPartialCompositeKey(ctx, objectType, attributes, handle)
+}
+
+// ledgerSnapshot reads keys as they were at a moment from their history; a key written after the moment, or deleted by then, reads as absent
+type ledgerSnapshot struct {
+	asOf time.Time
+}
+
+// parseAsOf reads an RFC3339 timestamp, or a YYYY-MM-DD date as the last instant of that day in UTC
+func parseAsOf(timestamp string) (time.Time, error) {
+	moment, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err == nil {
+		return moment.UTC(), nil
+	}
+
+	day, err := time.Parse(isoDateLayout, timestamp)
+	if err == nil {
+		return day.Add(24*time.Hour - time.Nanosecond), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp %q, expected RFC3339 (e.g. 2024-06-30T12:00:00Z) or YYYY-MM-DD", timestamp)
+}
+
+// getState returns the value the key had at the moment of the snapshot, or nil. The history is read newest first, so of the writes
+// with the latest timestamp not after the moment, which are in the same block or were stamped alike by their clients, the last one wins.
+func (snapshot ledgerSnapshot) getState(ctx contractapi.TransactionContextInterface, key string) ([]byte, error) {
+	iterator, err := ctx.GetStub().GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the history from worldstate db : %v", err)
+	}
+
+	defer iterator.Close()
+
+	var value []byte
+	var latest time.Time
+	var found bool
+
+	for iterator.HasNext() {
+		modification, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+
+		writtenAt := modification.Timestamp.AsTime()
+		if writtenAt.After(snapshot.asOf) || (found && !writtenAt.After(latest)) {
+			continue
+		}
+
+		found = true
+		latest = writtenAt
+		if modification.IsDelete {
+			value = nil
+		} else {
+			value = modification.Value
+		}
+	}
+
+	return value, nil
+}
+
+// getByPartialCompositeKey hands the values the keys of the partial composite key had at the moment of the snapshot to handle. The keys
+// are enumerated from the current state, so a key deleted since the moment is not read.
+func (snapshot ledgerSnapshot) getByPartialCompositeKey(ctx contractapi.TransactionContextInterface, objectType string, attributes []string,
+	handle func(value []byte) error) error {
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryRow, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+
+		jsonData, err := snapshot.getState(ctx, queryRow.Key)
+		if err != nil {
+			return err
+		}
+
+		if jsonData == nil {
+			continue
+		}
+
+		err = handle(jsonData)
+		if err != nil {
+			return fmt.Errorf("failed to fetch json data to struct : %v", err)
+		}
+	}
+
+	return nil
+}
+
+// getRecord reads the record stored under hashValue at the moment of the snapshot into record
+func (snapshot ledgerSnapshot) getRecord(ctx contractapi.TransactionContextInterface, hashValue string, record interface{}) error {
+	jsonData, err := snapshot.getState(ctx, hashValue)
+	if err != nil {
+		return err
+	}
+
+	if jsonData == nil {
+		return fmt.Errorf("there is not a record with the given hash value: %v", hashValue)
+	}
+
+	err = json.Unmarshal(jsonData, record)
+	if err != nil {
+		return fmt.Errorf("failed to fetch json data to struct : %v", err)
+	}
+
+	return nil
+}
+
+// studentMetaInfos returns the MetaInfo records of a student, by relation, as they were at the moment of the snapshot. The keys are
+// enumerated from the current state, since the MetaInfo records of a student are only ever added or rewritten.
+func (snapshot ledgerSnapshot) studentMetaInfos(ctx contractapi.TransactionContextInterface, hei string, studentID string) (map[string][]*MetaInfo, error) {
+	records := make(map[string][]*MetaInfo)
+
+	err := snapshot.getByPartialCompositeKey(ctx, "heiID", []string{hei, studentID}, func(value []byte) error {
+		var record MetaInfo
+		err := json.Unmarshal(value, &record)
+		if err != nil {
+			return err
+		}
+
+		records[record.Relation] = append(records[record.Relation], &record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// transcriptAsOf constructs the transcript of a student from the records as they were at the moment of the snapshot
+func (Transcript *SmartContract) transcriptAsOf(ctx contractapi.TransactionContextInterface, hei string, studentID string, snapshot ledgerSnapshot) (*StudentTranscript, error) {
+	records, err := snapshot.studentMetaInfos(ctx, hei, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("there is not a record of the student %s as of %s", studentID, snapshot.asOf.Format(time.RFC3339))
+	}
+
+	// A student info record missing at the moment is recorded in the completeness report, as for the transcript as it is now
+	var infoStudent *StudentInfo
+	current := currentMetaInfo(records["StudentInfo"])
+	if current != nil {
+		infoStudent = &StudentInfo{}
+		err = snapshot.getRecord(ctx, current.HashValue, infoStudent)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch student info record by hash value: %v", err)
+		}
+	}
+
+	infoCourses := []*CourseInfo{}
+	for _, record := range records["CourseInfo"] {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var course CourseInfo
+		err = snapshot.getRecord(ctx, record.HashValue, &course)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch course info record by hash value: %v", err)
+		}
+		infoCourses = append(infoCourses, &course)
+	}
+
+	coursesTaken := []*TakenCourse{}
+	for _, record := range records["TakenCourse"] {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var course TakenCourse
+		err = snapshot.getRecord(ctx, record.HashValue, &course)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
+		}
+		coursesTaken = append(coursesTaken, &course)
+	}
+
+	configKey, err := ctx.GetStub().CreateCompositeKey("heiConfig", []string{hei})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonConfig, err := snapshot.getState(ctx, configKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	config, err := decodeHEIConfig(hei, jsonConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	scales, err := loadGradingScalesOn(ctx, snapshot, config, snapshot.asOf.Format(isoDateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	return Transcript.assembleTranscript(ctx, snapshot, hei, infoStudent, infoCourses, coursesTaken, config, scales, nil)
+}
@@ -0,0 +1,135 @@
+package chaincodeTranscript
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestLedgerSnapshotGetState(t *testing.T) {
+	ledger := newTestLedger(t)
+	put := func(value string) time.Time {
+		ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+			if value == "" {
+				return ctx.GetStub().DelState("key")
+			}
+			return ctx.GetStub().PutState("key", []byte(value))
+		})
+		return ledger.stub.now
+	}
+
+	first := put("first")
+	second := put("second")
+
+	// Two writes stamped alike, e.g. in the same block
+	tied := put("tied-1")
+	ledger.stub.step = 0
+	put("tied-2")
+	ledger.stub.step = time.Hour
+
+	deleted := put("")
+
+	tests := []struct {
+		name string
+		asOf time.Time
+		want string // Empty for absent
+	}{
+		{name: "before the first write", asOf: first.Add(-time.Second)},
+		{name: "at the first write", asOf: first, want: "first"},
+		{name: "between two writes", asOf: second.Add(time.Minute), want: "second"},
+		{name: "writes with equal timestamps", asOf: tied, want: "tied-2"},
+		{name: "after the delete", asOf: deleted.Add(time.Hour)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := ledgerSnapshot{asOf: test.asOf}.getState(ledger.ctx(), "key")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(value) != test.want {
+				t.Errorf("got %q, want %q", value, test.want)
+			}
+		})
+	}
+}
+
+func TestGetStudentTranscriptAsOf(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.addCourse(190908809, "COMP2004", "BB", "9", 2)
+	coursesStored := ledger.stub.now
+
+	ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+	studentStored := ledger.stub.now
+
+	tests := []struct {
+		name        string
+		asOf        time.Time
+		wantErr     bool
+		wantMissing bool
+		wantCourses int
+	}{
+		{name: "before any record", asOf: coursesStored.Add(-3 * time.Hour), wantErr: true},
+		{name: "before the student info", asOf: coursesStored, wantMissing: true, wantCourses: 1},
+		{name: "after the student info", asOf: studentStored, wantCourses: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transcript, err := ledger.contract.GetStudentTranscriptAsOf(ledger.ctx(), testHEI, "190908809", test.asOf.Format(time.RFC3339))
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("got no error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if transcript.Completeness.MissingStudentInfo != test.wantMissing || len(transcript.Courses) != test.wantCourses {
+				t.Errorf("got MissingStudentInfo %v and %d courses, want %v and %d", transcript.Completeness.MissingStudentInfo,
+					len(transcript.Courses), test.wantMissing, test.wantCourses)
+			}
+		})
+	}
+}
+
+// A grading scale version registered after the moment is not used for the transcript as of the moment, even when it is effective from before it
+func TestGetStudentTranscriptAsOfReadsTheGradingScalesOfTheMoment(t *testing.T) {
+	ledger := newTestLedger(t)
+	grades := func(coefficient float64) []GradeDefinition {
+		return []GradeDefinition{{Code: "P-LOW", Coefficient: coefficient, Passing: true, CountsInGPA: true}}
+	}
+
+	ledger.addGradingScale("2020-01-01", false, grades(2))
+	ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+	ledger.addCourse(190908809, "COMP2004", "P-LOW", "0", 2)
+	recorded := ledger.stub.now
+
+	ledger.addGradingScale("2020-06-01", false, grades(3))
+
+	tests := []struct {
+		name     string
+		asOf     time.Time
+		wantCGPA float64
+	}{
+		{name: "before the backdated version", asOf: recorded, wantCGPA: 2},
+		{name: "after the backdated version", asOf: ledger.stub.now, wantCGPA: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transcript, err := ledger.contract.GetStudentTranscriptAsOf(ledger.ctx(), testHEI, "190908809", test.asOf.Format(time.RFC3339))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if transcript.CGPA != test.wantCGPA {
+				t.Errorf("got the CGPA %v, want %v", transcript.CGPA, test.wantCGPA)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Completeness of a transcript: the records that could not be joined into it
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// CompletenessReport lists what is missing from, or ambiguous in, the records a transcript is joined from
+type CompletenessReport struct {
+	Complete                bool                    `json:"complete"`
+	MissingStudentInfo      bool                    `json:"missing_student_info"`      // The student has no current StudentInfo record
+	OrphanTakenCourses      []TakenCourse           `json:"orphan_taken_courses"`      // Taken courses without a CourseInfo record; they are left out of the courses and the averages
+	DuplicateCatalogMatches []DuplicateCatalogMatch `json:"duplicate_catalog_matches"` // Course codes with more than one CourseInfo record; the first one is used
+}
+
+// DuplicateCatalogMatch is a course code that matches more than one current CourseInfo record of the student
+type DuplicateCatalogMatch struct {
+	CourseCode       string   `json:"course_code"`
+	CourseInfoHashes []string `json:"course_info_hashes"`
+}
+
+// GetStudentTranscriptStrict constructs a student's transcript like GetStudentTranscript, but fails instead of returning a transcript
+// with a missing StudentInfo record, orphan taken courses or a course code matching more than one CourseInfo record
+func (Transcript *SmartContract) GetStudentTranscriptStrict(ctx contractapi.TransactionContextInterface, hei string, studentID string) (*StudentTranscript, error) {
+	transcript, err := Transcript.buildStudentTranscript(ctx, hei, studentID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !transcript.Completeness.Complete {
+		return nil, fmt.Errorf("the transcript of the student %s is incomplete: %s", studentID, transcript.Completeness.describe())
+	}
+
+	return transcript, nil
+}
+
+func newCompletenessReport() CompletenessReport {
+	return CompletenessReport{OrphanTakenCourses: []TakenCourse{}, DuplicateCatalogMatches: []DuplicateCatalogMatch{}}
+}
+
+func (report *CompletenessReport) finish() {
+	report.Complete = !report.MissingStudentInfo && len(report.OrphanTakenCourses) == 0 && len(report.DuplicateCatalogMatches) == 0
+}
+
+// addDuplicate records the CourseInfo records matching a course code, once per course code
+func (report *CompletenessReport) addDuplicate(courseCode string, matches []*CourseInfo) {
+	for _, duplicate := range report.DuplicateCatalogMatches {
+		if duplicate.CourseCode == courseCode {
+			return
+		}
+	}
+
+	duplicate := DuplicateCatalogMatch{CourseCode: courseCode, CourseInfoHashes: []string{}}
+	for _, info := range matches {
+		duplicate.CourseInfoHashes = append(duplicate.CourseInfoHashes, info.HashValue)
+	}
+
+	report.DuplicateCatalogMatches = append(report.DuplicateCatalogMatches, duplicate)
+}
+
+func (report *CompletenessReport) describe() string {
+	problems := []string{}
+
+	if report.MissingStudentInfo {
+		problems = append(problems, "there is not a student info record")
+	}
+
+	for _, course := range report.OrphanTakenCourses {
+		problems = append(problems, fmt.Sprintf("the taken course %s (%s) has no course info record", course.CourseCode, course.HashValue))
+	}
+
+	for _, duplicate := range report.DuplicateCatalogMatches {
+		problems = append(problems, fmt.Sprintf("the course %s matches %d course info records", duplicate.CourseCode, len(duplicate.CourseInfoHashes)))
+	}
+
+	return strings.Join(problems, "; ")
+}
@@ -0,0 +1,126 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * HEI configuration: how the HEI's transcripts are computed
+// *
+// ------------------------------------------------------------------------------------------------------
+
+const (
+	weightingCredit = "credit"
+	weightingECTS   = "ects"
+)
+
+var gpaWeightings = []string{weightingCredit, weightingECTS}
+
+// HEIConfig is stored once per HEI under the "heiConfig" composite key; an HEI without one uses the defaults of defaultHEIConfig
+type HEIConfig struct {
+	Owner           string         `json:"owner"`
+	Weighting       string         `json:"weighting"`                                       // Course weight of the GPA: credit or ects
+	GradingScale    string         `json:"grading_scale,omitempty" metadata:",optional"`    // Scale id of the selected grading scale, empty for the default AA-FF scale
+	PointCheck      string         `json:"point_check,omitempty" metadata:",optional"`      // What happens to a taken course with an unexpected point: flag (default) or reject
+	Honors          *HonorRules    `json:"honors,omitempty" metadata:",optional"`           // Honor list rules, nil for defaultHonorRules
+	StandingRules   []StandingRule `json:"standing_rules,omitempty" metadata:",optional"`   // Probation thresholds, empty for defaultStandingRules
+	ClassThresholds []int          `json:"class_thresholds,omitempty" metadata:",optional"` // Earned loads from which a student is in the second, third, ... class, empty for defaultClassThresholds
+	MinCohortSize   int            `json:"min_cohort_size,omitempty" metadata:",optional"`  // Fewest students of a course statistic, 0 for defaultMinCohortSize
+}
+
+func defaultHEIConfig(hei string) *HEIConfig {
+	return &HEIConfig{Owner: hei, Weighting: weightingCredit}
+}
+
+// SetGPAWeighting selects whether the HEI's GPA and CGPA are weighted by course credits or by ECTS
+func (Transcript *SmartContract) SetGPAWeighting(ctx contractapi.TransactionContextInterface, owner string, weighting string) (bool, error) {
+	if !isOneOf(weighting, gpaWeightings) {
+		return false, fmt.Errorf("unknown GPA weighting %q, expected one of %v", weighting, gpaWeightings)
+	}
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	config.Weighting = weighting
+
+	return true, putHEIConfig(ctx, config)
+}
+
+func (Transcript *SmartContract) Get_HEI_Config(ctx contractapi.TransactionContextInterface, hei string) (*HEIConfig, error) {
+	return loadHEIConfig(ctx, hei)
+}
+
+func (config *HEIConfig) honorRules() *HonorRules {
+	if config.Honors == nil {
+		return &defaultHonorRules
+	}
+	return config.Honors
+}
+
+func (config *HEIConfig) classThresholds() []int {
+	if len(config.ClassThresholds) == 0 {
+		return defaultClassThresholds[config.Weighting]
+	}
+	return config.ClassThresholds
+}
+
+func (config *HEIConfig) minCohortSize() int {
+	if config.MinCohortSize == 0 {
+		return defaultMinCohortSize
+	}
+	return config.MinCohortSize
+}
+
+func loadHEIConfig(ctx contractapi.TransactionContextInterface, hei string) (*HEIConfig, error) {
+	configKey, err := ctx.GetStub().CreateCompositeKey("heiConfig", []string{hei})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonData, err := ctx.GetStub().GetState(configKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	return decodeHEIConfig(hei, jsonData)
+}
+
+// decodeHEIConfig reads a stored configuration over the defaults; an HEI without a stored configuration gets the defaults
+func decodeHEIConfig(hei string, jsonData []byte) (*HEIConfig, error) {
+	config := defaultHEIConfig(hei)
+	if jsonData == nil {
+		return config, nil
+	}
+
+	err := json.Unmarshal(jsonData, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+	}
+
+	return config, nil
+}
+
+func putHEIConfig(ctx contractapi.TransactionContextInterface, config *HEIConfig) error {
+	configKey, err := ctx.GetStub().CreateCompositeKey("heiConfig", []string{config.Owner})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonConfig, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(configKey, jsonConfig)
+	if err != nil {
+		return fmt.Errorf("failed to put HEI configuration to world state. %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Dates: validated at insert and stored as ISO-8601 (YYYY-MM-DD)
+// *
+// ------------------------------------------------------------------------------------------------------
+
+const isoDateLayout = "2006-01-02"
+
+// dottedDateLayout is the day-first DD.MM.YYYY format of the Turkish HEI records, e.g. 02.09.2022 is 2 September 2022
+const dottedDateLayout = "02.01.2006"
+
+// DateMigration reports what MigrateDatesToISO changed and which records it could not convert
+type DateMigration struct {
+	StudentInfosUpdated       int      `json:"student_infos_updated"`
+	ProgramEnrollmentsUpdated int      `json:"program_enrollments_updated"`
+	DegreeAwardsUpdated       int      `json:"degree_awards_updated"`
+	DegreeAwardsResealed      int      `json:"degree_awards_resealed"` // Awards whose transcript hash was re-sealed over the converted records
+	Unresolved                []string `json:"unresolved"`
+}
+
+// normalizeDate validates a date given either as YYYY-MM-DD or as day-first DD.MM.YYYY and returns it as YYYY-MM-DD
+func normalizeDate(value string) (string, error) {
+	for _, layout := range []string{isoDateLayout, dottedDateLayout} {
+		date, err := time.Parse(layout, value)
+		if err == nil {
+			return date.Format(isoDateLayout), nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid date %q, expected YYYY-MM-DD or DD.MM.YYYY", value)
+}
+
+// txDate returns the date of the running transaction as YYYY-MM-DD
+func txDate(ctx contractapi.TransactionContextInterface) (string, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the transaction timestamp: %v", err)
+	}
+
+	return timestamp.AsTime().UTC().Format(isoDateLayout), nil
+}
+
+// MigrateDatesToISO converts the date fields of the HEI's current StudentInfo, ProgramEnrollment and DegreeAward records to ISO-8601.
+// Every converted record is re-anchored: it is stored under its new hash value as a new version that supersedes the old one. A degree
+// award certifying a converted record is re-sealed over it, unless its transcript already changed since the award.
+func (Transcript *SmartContract) MigrateDatesToISO(ctx contractapi.TransactionContextInterface, owner string) (*DateMigration, error) {
+	migration := DateMigration{Unresolved: []string{}}
+	reason := "Migration: ISO-8601 dates"
+
+	// The converted records by student ID, and by student ID and program code, to re-seal the awards certifying them
+	students := make(map[string]StudentInfo)
+	enrollments := make(map[string]ProgramEnrollment)
+
+	for _, relation := range []string{"StudentInfo", "ProgramEnrollment", "DegreeAward"} {
+		records, err := getHEIRecordEntries(ctx, owner, relation)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			if record.SupersededBy != "" {
+				continue
+			}
+
+			switch relation {
+			case "StudentInfo":
+				var student StudentInfo
+				err = decodeRecord(ctx, record, &student)
+				if err != nil {
+					return nil, fmt.Errorf("error during fetch student info record by hash value: %v", err)
+				}
+
+				converted, err := normalizeDate(student.RegistrationDate)
+				if err != nil {
+					migration.Unresolved = append(migration.Unresolved, fmt.Sprintf("StudentInfo %s of the student %s: %v", record.HashValue, record.StudentID, err))
+					continue
+				}
+
+				if converted == student.RegistrationDate {
+					continue
+				}
+
+				student.RegistrationDate = converted
+				student.HashValue = ""
+				student.HashValue = StructToMD5(student)
+
+				_, err = supersedeRecord(ctx, &record.MetaInfo, student.HashValue, student, reason)
+				if err != nil {
+					return nil, err
+				}
+				students[record.StudentID] = student
+				migration.StudentInfosUpdated++
+
+			case "ProgramEnrollment":
+				var enrollment ProgramEnrollment
+				err = decodeRecord(ctx, record, &enrollment)
+				if err != nil {
+					return nil, fmt.Errorf("error during fetch program enrollment record by hash value: %v", err)
+				}
+
+				converted, err := normalizeDate(enrollment.StartDate)
+				if err != nil {
+					migration.Unresolved = append(migration.Unresolved, fmt.Sprintf("ProgramEnrollment %s of the student %s: %v", record.HashValue, record.StudentID, err))
+					continue
+				}
+
+				if converted == enrollment.StartDate {
+					continue
+				}
+
+				enrollment.StartDate = converted
+				enrollment.HashValue = ""
+				enrollment.HashValue = StructToMD5(enrollment)
+
+				_, err = supersedeRecord(ctx, &record.MetaInfo, enrollment.HashValue, enrollment, reason)
+				if err != nil {
+					return nil, err
+				}
+				enrollments[record.StudentID+"/"+enrollment.ProgramCode] = enrollment
+				migration.ProgramEnrollmentsUpdated++
+
+			case "DegreeAward":
+				var award DegreeAward
+				err = decodeRecord(ctx, record, &award)
+				if err != nil {
+					return nil, fmt.Errorf("error during fetch degree award record by hash value: %v", err)
+				}
+
+				converted, err := normalizeDate(award.GraduationDate)
+				if err != nil {
+					migration.Unresolved = append(migration.Unresolved, fmt.Sprintf("DegreeAward %s of the student %s: %v", record.HashValue, record.StudentID, err))
+					converted = award.GraduationDate
+				}
+
+				// The transcript is read as it was before this migration, which is not visible to the transaction that writes it
+				transcriptHash, err := Transcript.resealedTranscriptHash(ctx, owner, &award, func(transcript *StudentTranscript) {
+					if student, ok := students[record.StudentID]; ok {
+						transcript.InfoStudent = student
+					}
+					if transcript.Program != nil {
+						if enrollment, ok := enrollments[record.StudentID+"/"+transcript.Program.ProgramCode]; ok {
+							transcript.Program = &enrollment
+						}
+					}
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				if converted == award.GraduationDate && transcriptHash == award.TranscriptHash {
+					continue
+				}
+
+				if converted != award.GraduationDate {
+					migration.DegreeAwardsUpdated++
+				}
+				if transcriptHash != award.TranscriptHash {
+					migration.DegreeAwardsResealed++
+				}
+
+				award.GraduationDate = converted
+				award.TranscriptHash = transcriptHash
+
+				// The diploma number must keep resolving to the re-anchored award
+				err = supersedeDegreeAward(ctx, owner, &record.MetaInfo, award, reason)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return &migration, nil
+}
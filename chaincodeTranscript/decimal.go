@@ -0,0 +1,119 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Exact decimals: points are kept as written instead of being rounded to the nearest float32
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// Decimal is an exact decimal number in canonical form, e.g. "18.9". It is written to JSON as a string and read from a
+// string or, for records stored before points became decimals, from a number.
+type Decimal string
+
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(\.[0-9]+)?$`)
+
+// parseDecimal validates a decimal such as 18.90 and returns it in canonical form (18.9)
+func parseDecimal(value string) (Decimal, error) {
+	if !decimalPattern.MatchString(value) {
+		return "", fmt.Errorf("invalid decimal number %q", value)
+	}
+
+	number, _ := new(big.Rat).SetString(value)
+
+	return decimalFromRat(number), nil
+}
+
+// decimalFromRat formats a number with a finite decimal expansion without trailing zeros
+func decimalFromRat(number *big.Rat) Decimal {
+	text := number.FloatString(decimalPlaces(number))
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	if text == "-0" {
+		text = "0"
+	}
+	return Decimal(text)
+}
+
+// decimalPlaces counts the fraction digits needed to write the number exactly, which is the larger of the powers of 2 and 5 in its
+// denominator; a denominator with other factors (which parsing a decimal never produces) is cut off at 64 places
+func decimalPlaces(number *big.Rat) int {
+	denominator := new(big.Int).Set(number.Denom())
+	remainder := new(big.Int)
+	counts := make(map[int64]int)
+
+	for _, factor := range []int64{2, 5} {
+		divisor := big.NewInt(factor)
+		for {
+			quotient, _ := new(big.Int).QuoRem(denominator, divisor, remainder)
+			if remainder.Sign() != 0 {
+				break
+			}
+			denominator = quotient
+			counts[factor]++
+		}
+	}
+
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		return 64
+	}
+
+	if counts[2] > counts[5] {
+		return counts[2]
+	}
+	return counts[5]
+}
+
+func (decimal Decimal) rat() *big.Rat {
+	number, ok := new(big.Rat).SetString(string(decimal))
+	if !ok {
+		return new(big.Rat)
+	}
+	return number
+}
+
+// UnmarshalJSON accepts "18.9" as well as the number 18.9 written by earlier versions of the chaincode
+func (decimal *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+
+	// As for the other types, null leaves the decimal as it is
+	if text == "null" {
+		return nil
+	}
+
+	if !strings.HasPrefix(text, `"`) {
+		number, ok := new(big.Rat).SetString(text)
+		if !ok {
+			return fmt.Errorf("invalid decimal number %s", text)
+		}
+		*decimal = decimalFromRat(number)
+		return nil
+	}
+
+	text, err := strconv.Unquote(text)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := parseDecimal(text)
+	if err != nil {
+		return err
+	}
+
+	*decimal = parsed
+	return nil
+}
+
+// jsonNumber writes the decimal as a JSON number, the way points were written before they became decimals
+func (decimal Decimal) jsonNumber() json.Number {
+	return json.Number(decimal)
+}
@@ -0,0 +1,103 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Decimal
+		wantErr bool
+	}{
+		{value: "18.90", want: "18.9"},
+		{value: "12", want: "12"},
+		{value: "007.50", want: "7.5"},
+		{value: "-0.0", want: "0"},
+		{value: "+3.25", want: "3.25"},
+		{value: "0.1", want: "0.1"},
+		{value: "", wantErr: true},
+		{value: "1e2", wantErr: true},
+		{value: "12.", wantErr: true},
+		{value: "twelve", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := parseDecimal(test.value)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseDecimal(%q) error = %v, want an error: %v", test.value, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("parseDecimal(%q) = %q, want %q", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+// TestDecimalJSON reads the points of TakenCourse records as the current and the earlier versions of the chaincode wrote them
+func TestDecimalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    Decimal
+		wantErr bool
+	}{
+		{name: "string", json: `{"point":"18.9"}`, want: "18.9"},
+		{name: "string in non-canonical form", json: `{"point":"18.90"}`, want: "18.9"},
+		{name: "float32 number of an earlier version", json: `{"point":18.9}`, want: "18.9"},
+		{name: "integer number", json: `{"point":12}`, want: "12"},
+		{name: "number with an exponent", json: `{"point":1.5e1}`, want: "15"},
+		{name: "null", json: `{"point":null}`, want: ""},
+		{name: "missing", json: `{}`, want: ""},
+		{name: "invalid string", json: `{"point":"12,5"}`, wantErr: true},
+		{name: "boolean", json: `{"point":true}`, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var course TakenCourse
+			err := json.Unmarshal([]byte(test.json), &course)
+
+			if (err != nil) != test.wantErr {
+				t.Fatalf("json.Unmarshal(%s) error = %v, want an error: %v", test.json, err, test.wantErr)
+			}
+			if course.Point != test.want {
+				t.Errorf("json.Unmarshal(%s) point = %q, want %q", test.json, course.Point, test.want)
+			}
+		})
+	}
+}
+
+func TestDecimalJSONRoundTrip(t *testing.T) {
+	for _, point := range []Decimal{"0", "9", "18.9", "0.125", "-1.5"} {
+		data, err := json.Marshal(TakenCourse{Point: point})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var course TakenCourse
+		err = json.Unmarshal(data, &course)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if course.Point != point {
+			t.Errorf("round trip of %q gave %q", point, course.Point)
+		}
+
+		// Written as a JSON number, as earlier versions did, it reads back the same
+		number, err := json.Marshal(point.jsonNumber())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded Decimal
+		err = json.Unmarshal(number, &decoded)
+		if err != nil || decoded != point {
+			t.Errorf("number %s read back as %q, %v", number, decoded, err)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Degree awards: a conferred degree seals the hash of the transcript it certifies
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// DegreeAward records that a degree was conferred; TranscriptHash is the hash of the transcript at the moment of the award
+type DegreeAward struct {
+	StudentID      int     `json:"student_id"`
+	ProgramCode    string  `json:"program_code"` // Empty when the degree certifies the whole transcript
+	DegreeTitle    string  `json:"degree_title"`
+	GraduationDate string  `json:"graduation_date"` // ISO-8601 date, YYYY-MM-DD
+	FinalCGPA      float64 `json:"final_cgpa"`
+	HonorsClass    string  `json:"honors_class"`
+	DiplomaNumber  string  `json:"diploma_number"`
+	TranscriptHash string  `json:"transcript_hash"`
+	SealedAt       string  `json:"sealed_at"` // Transaction timestamp of the award, RFC3339
+	HashValue      string  `json:"hash_value"`
+}
+
+// DegreeVerification compares the transcript hash sealed by a degree award with the hash of the current transcript
+type DegreeVerification struct {
+	Award                 DegreeAward `json:"award"`
+	CurrentTranscriptHash string      `json:"current_transcript_hash"`
+	Intact                bool        `json:"intact"` // False when the transcript changed after the award
+}
+
+func (Transcript *SmartContract) AwardDegree(ctx contractapi.TransactionContextInterface, owner string, studentId int, programCode string, degreeTitle string,
+	graduationDate string, finalCGPA float64, honorsClass string, diplomaNumber string) (bool, error) {
+
+	var award DegreeAward
+
+	if diplomaNumber == "" {
+		return false, fmt.Errorf("diploma number of the degree award must not be empty")
+	}
+
+	if finalCGPA < 0 {
+		return false, fmt.Errorf("final CGPA must not be negative: %v", finalCGPA)
+	}
+
+	diplomaKey, err := ctx.GetStub().CreateCompositeKey("diploma", []string{owner, diplomaNumber})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	awardHash, err := ctx.GetStub().GetState(diplomaKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if awardHash != nil {
+		return false, fmt.Errorf("the diploma number %s was already issued", diplomaNumber)
+	}
+
+	awards, err := Transcript.getStudentDegreeAwards(ctx, owner, strconv.Itoa(studentId))
+	if err != nil {
+		return false, err
+	}
+
+	for _, existing := range awards {
+		if existing.ProgramCode == programCode {
+			return false, fmt.Errorf("a degree was already awarded to the student for the program %q with the diploma number %s", programCode, existing.DiplomaNumber)
+		}
+	}
+
+	transcriptHash, err := Transcript.currentTranscriptHash(ctx, owner, strconv.Itoa(studentId), programCode)
+	if err != nil {
+		return false, err
+	}
+
+	sealedAt, err := txTimestamp(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	award.StudentID = studentId
+	award.ProgramCode = programCode
+	award.DegreeTitle = degreeTitle
+	award.GraduationDate, err = normalizeDate(graduationDate)
+	if err != nil {
+		return false, err
+	}
+	award.FinalCGPA = finalCGPA
+	award.HonorsClass = honorsClass
+	award.DiplomaNumber = diplomaNumber
+	award.TranscriptHash = transcriptHash
+	award.SealedAt = sealedAt
+
+	award.HashValue = StructToMD5(award)
+
+	err = putRecordWithMeta(ctx, owner, strconv.Itoa(studentId), "DegreeAward", award.HashValue, award)
+	if err != nil {
+		return false, err
+	}
+
+	err = ctx.GetStub().PutState(diplomaKey, []byte(award.HashValue))
+	if err != nil {
+		return false, fmt.Errorf("failed to put diploma number to world state. %v", err)
+	}
+
+	return true, nil
+}
+
+func (Transcript *SmartContract) Get_Student_DegreeAwards(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*DegreeAward, error) {
+	awards, err := Transcript.getStudentDegreeAwards(ctx, hei, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(awards) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return awards, nil
+}
+
+// VerifyDegreeAward recomputes the hash of the transcript certified by a diploma and reports whether it still matches the sealed one
+func (Transcript *SmartContract) VerifyDegreeAward(ctx contractapi.TransactionContextInterface, hei string, diplomaNumber string) (*DegreeVerification, error) {
+	var verification DegreeVerification
+
+	diplomaKey, err := ctx.GetStub().CreateCompositeKey("diploma", []string{hei, diplomaNumber})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	awardHash, err := ctx.GetStub().GetState(diplomaKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if awardHash == nil {
+		return nil, fmt.Errorf("there is not a degree award with the diploma number %s", diplomaNumber)
+	}
+
+	err = getRecordByHashValue(ctx, string(awardHash), &verification.Award)
+	if err != nil {
+		return nil, fmt.Errorf("error during fetch degree award record by hash value: %v", err)
+	}
+
+	verification.CurrentTranscriptHash, err = Transcript.currentTranscriptHash(ctx, hei, strconv.Itoa(verification.Award.StudentID), verification.Award.ProgramCode)
+	if err != nil {
+		return nil, err
+	}
+
+	verification.Intact = verification.CurrentTranscriptHash == verification.Award.TranscriptHash
+
+	return &verification, nil
+}
+
+func (Transcript *SmartContract) getStudentDegreeAwards(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*DegreeAward, error) {
+	var awards []*DegreeAward
+
+	records, err := getStudentRecordEntries(ctx, hei, "DegreeAward", studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var award DegreeAward
+		err = decodeRecord(ctx, record, &award)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch degree award record by hash value: %v", err)
+		}
+
+		awards = append(awards, &award)
+	}
+
+	return awards, nil
+}
+
+// currentTranscriptHash hashes the student's transcript (or program transcript when programCode is not empty) as it is now
+func (Transcript *SmartContract) currentTranscriptHash(ctx contractapi.TransactionContextInterface, hei string, studentID string, programCode string) (string, error) {
+	transcript, err := Transcript.certifiedTranscript(ctx, hei, studentID, programCode)
+	if err != nil {
+		return "", err
+	}
+
+	return TranscriptToMD5(transcript)
+}
+
+// certifiedTranscript builds the transcript a degree award certifies: the program transcript when programCode is not empty, else the whole one
+func (Transcript *SmartContract) certifiedTranscript(ctx contractapi.TransactionContextInterface, hei string, studentID string, programCode string) (*StudentTranscript, error) {
+	if programCode == "" {
+		return Transcript.GetStudentTranscript(ctx, hei, studentID)
+	}
+
+	return Transcript.GetStudentProgramTranscript(ctx, hei, studentID, programCode)
+}
+
+// resealedTranscriptHash returns the transcript hash an award seals after a change that leaves the certified records as they are, e.g. a
+// format migration; apply makes the change to the transcript as it is now. When the transcript already changed since the award was sealed,
+// the sealed hash is returned as it is, so that VerifyDegreeAward keeps reporting that change.
+func (Transcript *SmartContract) resealedTranscriptHash(ctx contractapi.TransactionContextInterface, hei string, award *DegreeAward,
+	apply func(transcript *StudentTranscript)) (string, error) {
+
+	transcript, err := Transcript.certifiedTranscript(ctx, hei, strconv.Itoa(award.StudentID), award.ProgramCode)
+	if err != nil {
+		return "", err
+	}
+
+	transcriptHash, err := TranscriptToMD5(transcript)
+	if err != nil {
+		return "", err
+	}
+
+	if transcriptHash != award.TranscriptHash {
+		return award.TranscriptHash, nil
+	}
+
+	apply(transcript)
+
+	return TranscriptToMD5(transcript)
+}
+
+// resealDegreeAwards re-seals the student's intact degree awards after a change that leaves the certified records as they are (see
+// resealedTranscriptHash) and returns how many it re-sealed. A re-sealed award is a new version of the award, whose reason records the change.
+func (Transcript *SmartContract) resealDegreeAwards(ctx contractapi.TransactionContextInterface, owner string, studentID string, reason string,
+	apply func(transcript *StudentTranscript)) (int, error) {
+
+	var resealed int
+
+	records, err := getStudentRecordEntries(ctx, owner, "DegreeAward", studentID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var award DegreeAward
+		err = decodeRecord(ctx, record, &award)
+		if err != nil {
+			return 0, fmt.Errorf("error during fetch degree award record by hash value: %v", err)
+		}
+
+		transcriptHash, err := Transcript.resealedTranscriptHash(ctx, owner, &award, apply)
+		if err != nil {
+			return 0, err
+		}
+
+		if transcriptHash == award.TranscriptHash {
+			continue
+		}
+
+		award.TranscriptHash = transcriptHash
+
+		err = supersedeDegreeAward(ctx, owner, &record.MetaInfo, award, reason)
+		if err != nil {
+			return 0, err
+		}
+		resealed++
+	}
+
+	return resealed, nil
+}
+
+// supersedeDegreeAward stores a changed award as the next version of the award indexed by current, and points its diploma number to it
+func supersedeDegreeAward(ctx contractapi.TransactionContextInterface, owner string, current *MetaInfo, award DegreeAward, reason string) error {
+	award.HashValue = ""
+	award.HashValue = StructToMD5(award)
+
+	_, err := supersedeRecord(ctx, current, award.HashValue, award, reason)
+	if err != nil {
+		return err
+	}
+
+	diplomaKey, err := ctx.GetStub().CreateCompositeKey("diploma", []string{owner, award.DiplomaNumber})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(diplomaKey, []byte(award.HashValue))
+	if err != nil {
+		return fmt.Errorf("failed to put diploma number to world state. %v", err)
+	}
+
+	return nil
+}
+
+// sealedTranscript is the part of a StudentTranscript certified by its hash; the averages are left out since they are derived from the courses
+type sealedTranscript struct {
+	InfoStudent StudentInfo        `json:"student_informations"`
+	Program     *ProgramEnrollment `json:"program,omitempty"`
+	Courses     []sealedCourse     `json:"taken_courses"`
+}
+
+// sealedCourse is a CombinedCourseRecords with the point written as a JSON number, as it was before points became decimals,
+// so that the hashes sealed by earlier degree awards stay valid
+type sealedCourse struct {
+	CourseCode    string      `json:"course_code"`
+	CourseName    string      `json:"course_name"`
+	CourseType    string      `json:"course_type"`
+	ECTS          int         `json:"ects"`
+	Credit        int         `json:"credit"`
+	Grade         string      `json:"grade"`
+	Point         json.Number `json:"point"`
+	TakenSemester int         `json:"taken_semester"`
+	Term          string      `json:"term,omitempty"`
+	Section       string      `json:"section,omitempty"`
+	Instructor    string      `json:"instructor,omitempty"`
+	Language      string      `json:"language,omitempty"`
+	DeliveryMode  string      `json:"delivery_mode,omitempty"`
+}
+
+// TranscriptToMD5 hashes the records certified by a transcript (student info, program and courses), independently of the order in which the world state returned the courses
+func TranscriptToMD5(transcript *StudentTranscript) (string, error) {
+	sealed := sealedTranscript{InfoStudent: transcript.InfoStudent, Program: transcript.Program}
+
+	for _, course := range transcript.Courses {
+		sealed.Courses = append(sealed.Courses, sealedCourse{CourseCode: course.CourseCode, CourseName: course.CourseName, CourseType: course.CourseType,
+			ECTS: course.ECTS, Credit: course.Credit, Grade: course.Grade, Point: course.Point.jsonNumber(), TakenSemester: course.TakenSemester,
+			Term: course.Term, Section: course.Section, Instructor: course.Instructor, Language: course.Language, DeliveryMode: course.DeliveryMode})
+	}
+
+	sort.SliceStable(sealed.Courses, func(i, j int) bool {
+		if sealed.Courses[i].TakenSemester != sealed.Courses[j].TakenSemester {
+			return sealed.Courses[i].TakenSemester < sealed.Courses[j].TakenSemester
+		}
+		if sealed.Courses[i].CourseCode != sealed.Courses[j].CourseCode {
+			return sealed.Courses[i].CourseCode < sealed.Courses[j].CourseCode
+		}
+		return sealed.Courses[i].Grade < sealed.Courses[j].Grade
+	})
+
+	jsonTranscript, err := json.Marshal(sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	return StringToMD5(string(jsonTranscript)), nil
+}
@@ -0,0 +1,128 @@
+package chaincodeTranscript
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// awardedLedger holds two students with degree awards, stored the way the chaincode stored them before dates were converted to ISO-8601
+// and before departments had codes. The transcript of the student 190908810 changed after the award.
+func awardedLedger(t *testing.T) *testLedger {
+	ledger := newTestLedger(t)
+
+	for _, studentID := range []int{190908809, 190908810} {
+		student := StudentInfo{Faculty: "Faculty of Engineering and Architecture", Department: "Department of Computer Engineering", StudentID: studentID,
+			StudentSurname: "Selvi", StudentName: "Ahmet", NationalID: "44262495576", RegistrationDate: "02.09.2022", RegistrationType: "Major / OSYM",
+			ProgramType: "Undergraduate", Class: 4, StudentSemester: 8}
+		student.HashValue = StructToMD5(student)
+		ledger.putLegacyRecord(studentID, "StudentInfo", student.HashValue, student)
+
+		enrollment := ProgramEnrollment{StudentID: studentID, ProgramCode: "CENG-BSc", Faculty: student.Faculty, Department: student.Department,
+			ProgramType: "Undergraduate", EnrollmentType: "Major", RegistrationType: "OSYM", StartDate: "02.09.2022", Status: "Active"}
+		enrollment.HashValue = StructToMD5(enrollment)
+		ledger.putLegacyRecord(studentID, "ProgramEnrollment", enrollment.HashValue, enrollment)
+
+		ledger.addCourse(studentID, "COMP2004", "BB", "9", 4)
+	}
+
+	awards := []struct {
+		studentID   int
+		programCode string
+		diploma     string
+	}{{190908809, "", "FBU-1"}, {190908809, "CENG-BSc", "FBU-2"}, {190908810, "", "FBU-3"}}
+
+	for _, award := range awards {
+		ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(ledger.contract.AwardDegree(ctx, testHEI, award.studentID, award.programCode, "Bachelor of Science", "2026-06-30", 3, "", award.diploma))
+		})
+	}
+
+	ledger.addCourse(190908810, "COMP3001", "AA", "12", 6)
+
+	return ledger
+}
+
+func TestMigrationsKeepDegreeAwardsIntact(t *testing.T) {
+	contract := new(SmartContract)
+
+	tests := []struct {
+		name     string
+		setup    func(ledger *testLedger) // Run before the migration, when set
+		migrate  func(ctx contractapi.TransactionContextInterface) (int, error)
+		reason   string
+		resealed int
+	}{
+		{
+			name: "ISO-8601 dates",
+			migrate: func(ctx contractapi.TransactionContextInterface) (int, error) {
+				migration, err := contract.MigrateDatesToISO(ctx, testHEI)
+				if err != nil {
+					return 0, err
+				}
+				return migration.DegreeAwardsResealed, nil
+			},
+			reason:   "Migration: ISO-8601 dates",
+			resealed: 2,
+		},
+		{
+			name: "department codes",
+			setup: func(ledger *testLedger) {
+				ledger.addFaculty()
+				ledger.addDepartment("CENG", "Department of Computer Engineering", "COMP")
+			},
+			migrate: func(ctx contractapi.TransactionContextInterface) (int, error) {
+				migration, err := contract.MigrateDepartmentCodes(ctx, testHEI)
+				if err != nil {
+					return 0, err
+				}
+				return migration.DegreeAwardsResealed, nil
+			},
+			reason:   "Migration: faculty and department codes",
+			resealed: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := awardedLedger(t)
+
+			if test.setup != nil {
+				test.setup(ledger)
+			}
+
+			var resealed int
+			ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+				var err error
+				resealed, err = test.migrate(ctx)
+				return err
+			})
+
+			if resealed != test.resealed {
+				t.Errorf("got %d awards re-sealed, want %d", resealed, test.resealed)
+			}
+
+			// The award of the student whose transcript changed after it is not re-sealed, and keeps reporting the change
+			for diploma, intact := range map[string]bool{"FBU-1": true, "FBU-2": true, "FBU-3": false} {
+				verification, err := ledger.contract.VerifyDegreeAward(ledger.ctx(), testHEI, diploma)
+				if err != nil {
+					t.Fatalf("VerifyDegreeAward(%s): %v", diploma, err)
+				}
+				if verification.Intact != intact {
+					t.Errorf("VerifyDegreeAward(%s).Intact = %v, want %v", diploma, verification.Intact, intact)
+				}
+			}
+
+			// A re-sealed award is a new version, which records why it was written
+			entries, err := getStudentRecordEntries(ledger.ctx(), testHEI, "DegreeAward", "190908809")
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, entry := range entries {
+				if entry.SupersededBy == "" && entry.Reason != test.reason {
+					t.Errorf("current award %s has the reason %q, want %q", entry.HashValue, entry.Reason, test.reason)
+				}
+			}
+		})
+	}
+}
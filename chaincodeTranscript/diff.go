@@ -0,0 +1,280 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Transcript diffs: what changed on a student's transcript between two states of the ledger
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// TranscriptDiff lists the differences between two states of a student's transcript. A taken course is identified by its course code
+// and semester, so a corrected grade shows as a modified course while a repeated course in a later semester shows as an added one.
+type TranscriptDiff struct {
+	StudentID       string                  `json:"student_id"`
+	From            string                  `json:"from"` // Moment of the earlier state, RFC3339
+	To              string                  `json:"to"`   // Moment of the later state, RFC3339
+	AddedCourses    []CombinedCourseRecords `json:"added_courses"`
+	RemovedCourses  []CombinedCourseRecords `json:"removed_courses"`
+	ModifiedCourses []CourseChange          `json:"modified_courses"`
+	StudentChanges  []FieldChange           `json:"student_changes"` // Changed fields of the student's personal data
+	GPA             GPADelta                `json:"gpa"`
+}
+
+// CourseChange lists the changed fields of a taken course present in both states
+type CourseChange struct {
+	CourseCode    string        `json:"course_code"`
+	TakenSemester int           `json:"taken_semester"`
+	Changes       []FieldChange `json:"changes"`
+}
+
+// FieldChange is one changed field, named by its JSON name, with its values in the two states
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// GPADelta compares the CGPA, and the GPA of the terms whose GPA changed, between the two states
+type GPADelta struct {
+	FromCGPA float64         `json:"from_cgpa"`
+	ToCGPA   float64         `json:"to_cgpa"`
+	Delta    float64         `json:"delta"`
+	Terms    []TermGPAChange `json:"terms"`
+}
+
+// TermGPAChange is the GPA of a semester in the two states; a semester without courses in one of the states has a GPA of 0 there
+type TermGPAChange struct {
+	Semester int     `json:"semester"`
+	FromGPA  float64 `json:"from_gpa"`
+	ToGPA    float64 `json:"to_gpa"`
+	Delta    float64 `json:"delta"`
+}
+
+// GetStudentTranscriptDiff compares two states of a student's transcript. Each state is addressed by a timestamp (RFC3339, or YYYY-MM-DD
+// for the end of that day) or by the ID of a transaction that wrote the student's records or the HEI configuration; the state then
+// includes that transaction. An empty "to" compares with the current state.
+func (Transcript *SmartContract) GetStudentTranscriptDiff(ctx contractapi.TransactionContextInterface, hei string, studentID string, from string, to string) (*TranscriptDiff, error) {
+	fromSnapshot, err := resolveSnapshot(ctx, hei, studentID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toSnapshot, err := resolveSnapshot(ctx, hei, studentID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if toSnapshot.asOf.Before(fromSnapshot.asOf) {
+		return nil, fmt.Errorf("the \"to\" state (%s) is earlier than the \"from\" state (%s)", toSnapshot.asOf.Format(time.RFC3339Nano), fromSnapshot.asOf.Format(time.RFC3339Nano))
+	}
+
+	fromTranscript, err := Transcript.transcriptAsOf(ctx, hei, studentID, fromSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	toTranscript, err := Transcript.transcriptAsOf(ctx, hei, studentID, toSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := TranscriptDiff{
+		StudentID:       studentID,
+		From:            fromSnapshot.asOf.Format(time.RFC3339Nano),
+		To:              toSnapshot.asOf.Format(time.RFC3339Nano),
+		AddedCourses:    []CombinedCourseRecords{},
+		RemovedCourses:  []CombinedCourseRecords{},
+		ModifiedCourses: []CourseChange{},
+		StudentChanges:  fieldChanges(fromTranscript.InfoStudent, toTranscript.InfoStudent),
+		GPA:             gpaDelta(fromTranscript, toTranscript),
+	}
+
+	fromCourses := coursesByIdentity(fromTranscript.Courses)
+	toCourses := coursesByIdentity(toTranscript.Courses)
+
+	for _, identity := range sortedIdentities(fromCourses, toCourses) {
+		before, after := fromCourses[identity], toCourses[identity]
+
+		for index := 0; index < len(before) || index < len(after); index++ {
+			switch {
+			case index >= len(after):
+				diff.RemovedCourses = append(diff.RemovedCourses, before[index])
+			case index >= len(before):
+				diff.AddedCourses = append(diff.AddedCourses, after[index])
+			default:
+				changes := fieldChanges(before[index], after[index])
+				if len(changes) > 0 {
+					diff.ModifiedCourses = append(diff.ModifiedCourses, CourseChange{CourseCode: after[index].CourseCode, TakenSemester: after[index].TakenSemester, Changes: changes})
+				}
+			}
+		}
+	}
+
+	return &diff, nil
+}
+
+// resolveSnapshot addresses a state of the ledger by a timestamp, by the ID of a transaction that wrote the student's MetaInfo
+// records or the HEI configuration, or, when empty, by the time of the running transaction
+func resolveSnapshot(ctx contractapi.TransactionContextInterface, hei string, studentID string, point string) (ledgerSnapshot, error) {
+	if point == "" {
+		timestamp, err := ctx.GetStub().GetTxTimestamp()
+		if err != nil {
+			return ledgerSnapshot{}, fmt.Errorf("failed to read the transaction timestamp: %v", err)
+		}
+		return ledgerSnapshot{asOf: timestamp.AsTime().UTC()}, nil
+	}
+
+	asOf, err := parseAsOf(point)
+	if err == nil {
+		return ledgerSnapshot{asOf: asOf}, nil
+	}
+
+	keys := []string{}
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("heiID", []string{hei, studentID})
+	if err != nil {
+		return ledgerSnapshot{}, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	for iterator.HasNext() {
+		queryRow, err := iterator.Next()
+		if err != nil {
+			iterator.Close()
+			return ledgerSnapshot{}, fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+		keys = append(keys, queryRow.Key)
+	}
+
+	iterator.Close()
+
+	configKey, err := ctx.GetStub().CreateCompositeKey("heiConfig", []string{hei})
+	if err != nil {
+		return ledgerSnapshot{}, fmt.Errorf("failed to create composite key: %v", err)
+	}
+	keys = append(keys, configKey)
+
+	for _, key := range keys {
+		history, err := ctx.GetStub().GetHistoryForKey(key)
+		if err != nil {
+			return ledgerSnapshot{}, fmt.Errorf("failed to read the history from worldstate db : %v", err)
+		}
+
+		for history.HasNext() {
+			modification, err := history.Next()
+			if err != nil {
+				history.Close()
+				return ledgerSnapshot{}, fmt.Errorf("failed to iterate over the returned records : %v", err)
+			}
+
+			if modification.TxId == point {
+				history.Close()
+				return ledgerSnapshot{asOf: modification.Timestamp.AsTime().UTC()}, nil
+			}
+		}
+
+		history.Close()
+	}
+
+	return ledgerSnapshot{}, fmt.Errorf("%q is neither a timestamp nor the ID of a transaction on the records of the student %s", point, studentID)
+}
+
+// coursesByIdentity groups taken courses by course code and semester, keeping their order within the transcript
+func coursesByIdentity(courses []CombinedCourseRecords) map[string][]CombinedCourseRecords {
+	grouped := make(map[string][]CombinedCourseRecords)
+
+	for _, course := range courses {
+		identity := course.CourseCode + "/" + strconv.Itoa(course.TakenSemester)
+		grouped[identity] = append(grouped[identity], course)
+	}
+
+	return grouped
+}
+
+func sortedIdentities(groups ...map[string][]CombinedCourseRecords) []string {
+	seen := make(map[string]bool)
+	identities := []string{}
+
+	for _, group := range groups {
+		for identity := range group {
+			if !seen[identity] {
+				seen[identity] = true
+				identities = append(identities, identity)
+			}
+		}
+	}
+
+	sort.Strings(identities)
+
+	return identities
+}
+
+// fieldChanges compares two records of the same struct type field by field; the hash value is left out as it changes with any field
+func fieldChanges(before interface{}, after interface{}) []FieldChange {
+	changes := []FieldChange{}
+
+	beforeValues := reflect.ValueOf(before)
+	afterValues := reflect.ValueOf(after)
+
+	for i := 0; i < beforeValues.NumField(); i++ {
+		field := beforeValues.Type().Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "hash_value" {
+			continue
+		}
+
+		beforeValue := fmt.Sprintf("%v", beforeValues.Field(i).Interface())
+		afterValue := fmt.Sprintf("%v", afterValues.Field(i).Interface())
+		if beforeValue != afterValue {
+			changes = append(changes, FieldChange{Field: name, From: beforeValue, To: afterValue})
+		}
+	}
+
+	return changes
+}
+
+func gpaDelta(before *StudentTranscript, after *StudentTranscript) GPADelta {
+	delta := GPADelta{FromCGPA: before.CGPA, ToCGPA: after.CGPA, Delta: roundTo2(after.CGPA - before.CGPA), Terms: []TermGPAChange{}}
+
+	terms := make(map[int]*TermGPAChange)
+	semesters := []int{}
+
+	termOf := func(semester int) *TermGPAChange {
+		term, ok := terms[semester]
+		if !ok {
+			term = &TermGPAChange{Semester: semester}
+			terms[semester] = term
+			semesters = append(semesters, semester)
+		}
+		return term
+	}
+
+	for _, block := range before.Terms {
+		termOf(block.Semester).FromGPA = block.Totals.GPA
+	}
+
+	for _, block := range after.Terms {
+		termOf(block.Semester).ToGPA = block.Totals.GPA
+	}
+
+	sort.Ints(semesters)
+
+	for _, semester := range semesters {
+		term := terms[semester]
+		term.Delta = roundTo2(term.ToGPA - term.FromGPA)
+		if term.Delta != 0 {
+			delta.Terms = append(delta.Terms, *term)
+		}
+	}
+
+	return delta
+}
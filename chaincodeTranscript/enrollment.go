@@ -0,0 +1,323 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Program enrollments: a student may study several programs at once (major, double major, minor, double degree)
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// ProgramEnrollment records a student's registration to one program, each with its own registration type, start date and status
+type ProgramEnrollment struct {
+	StudentID        int    `json:"student_id"`
+	ProgramCode      string `json:"program_code"`
+	Faculty          string `json:"faculty"`
+	Department       string `json:"department"`
+	ProgramType      string `json:"program_type"`      // Undergraduate, Graduate, ...
+	EnrollmentType   string `json:"enrollment_type"`   // Major, Double Major, Minor, Double Degree
+	RegistrationType string `json:"registration_type"` // OSYM, Internal, Transfer, ...
+	StartDate        string `json:"start_date"`        // ISO-8601 date, YYYY-MM-DD
+	Status           string `json:"status"`            // Active, Suspended, Withdrawn, Graduated
+	HashValue        string `json:"hash_value"`
+}
+
+// CourseAttribution links a TakenCourse record to a program the student is enrolled in; a taken course may be attributed to more than one program
+type CourseAttribution struct {
+	StudentID       int    `json:"student_id"`
+	ProgramCode     string `json:"program_code"`
+	CourseCode      string `json:"course_code"`
+	TakenCourseHash string `json:"taken_course_hash"`
+	HashValue       string `json:"hash_value"`
+}
+
+var enrollmentTypes = []string{"Major", "Double Major", "Minor", "Double Degree"}
+
+var enrollmentStatuses = []string{"Active", "Suspended", "Withdrawn", "Graduated"}
+
+func isOneOf(value string, allowed []string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func (Transcript *SmartContract) InsertNewRecordProgramEnrollment(ctx contractapi.TransactionContextInterface, owner string, studentId int, programCode string,
+	faculty string, department string, programType string, enrollmentType string, registrationType string, startDate string, status string) (bool, error) {
+
+	var enrollment ProgramEnrollment
+
+	if programCode == "" {
+		return false, fmt.Errorf("program code of the enrollment must not be empty")
+	}
+
+	if !isOneOf(enrollmentType, enrollmentTypes) {
+		return false, fmt.Errorf("unknown enrollment type %q, expected one of %v", enrollmentType, enrollmentTypes)
+	}
+
+	if !isOneOf(status, enrollmentStatuses) {
+		return false, fmt.Errorf("unknown enrollment status %q, expected one of %v", status, enrollmentStatuses)
+	}
+
+	students, err := getStudentMetaInfos(ctx, owner, "StudentInfo", strconv.Itoa(studentId))
+	if err != nil {
+		return false, err
+	}
+
+	if currentMetaInfo(students) == nil {
+		return false, fmt.Errorf("there is not a student info record of the student %d to enroll", studentId)
+	}
+
+	existing, err := Transcript.getStudentProgramEnrollment(ctx, owner, strconv.Itoa(studentId), programCode)
+	if err != nil {
+		return false, fmt.Errorf("%v", err)
+	}
+
+	if existing != nil {
+		return false, fmt.Errorf("the student is already enrolled to the program %s", programCode)
+	}
+
+	enrollment.StudentID = studentId
+	enrollment.ProgramCode = programCode
+	enrollment.Faculty = faculty
+	enrollment.Department = department
+	enrollment.ProgramType = programType
+	enrollment.EnrollmentType = enrollmentType
+	enrollment.RegistrationType = registrationType
+	enrollment.StartDate, err = normalizeDate(startDate)
+	if err != nil {
+		return false, err
+	}
+	enrollment.Status = status
+
+	enrollment.HashValue = StructToMD5(enrollment)
+
+	err = putRecordWithMeta(ctx, owner, strconv.Itoa(studentId), "ProgramEnrollment", enrollment.HashValue, enrollment)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (Transcript *SmartContract) AttributeTakenCourseToProgram(ctx contractapi.TransactionContextInterface, owner string, studentId int, takenCourseHash string, programCode string) (bool, error) {
+	var attribution CourseAttribution
+
+	course, err := Transcript.Get_TakenCourse_ByHashValue(ctx, takenCourseHash)
+	if err != nil {
+		return false, fmt.Errorf("%v", err)
+	}
+
+	if course.StudentID != studentId {
+		return false, fmt.Errorf("the taken course %s does not belong to the student %d", takenCourseHash, studentId)
+	}
+
+	// Hash values are global, so the record must be checked to be one of the owner's
+	IsOwned, err := Transcript.IsRecordExists(ctx, owner, strconv.Itoa(studentId), takenCourseHash)
+	if err != nil {
+		return false, fmt.Errorf("%v", err)
+	}
+
+	if !IsOwned {
+		return false, fmt.Errorf("the taken course %s is not a record of %s", takenCourseHash, owner)
+	}
+
+	enrollment, err := Transcript.getStudentProgramEnrollment(ctx, owner, strconv.Itoa(studentId), programCode)
+	if err != nil {
+		return false, fmt.Errorf("%v", err)
+	}
+
+	if enrollment == nil {
+		return false, fmt.Errorf("the student %d is not enrolled to the program %s", studentId, programCode)
+	}
+
+	attribution.StudentID = studentId
+	attribution.ProgramCode = programCode
+	attribution.CourseCode = course.CourseCode
+	attribution.TakenCourseHash = takenCourseHash
+
+	attribution.HashValue = StructToMD5(attribution)
+
+	IsExist, err := Transcript.IsRecordExists(ctx, owner, strconv.Itoa(studentId), attribution.HashValue)
+	if err != nil {
+		return false, fmt.Errorf("%v", err)
+	}
+
+	if IsExist {
+		return false, fmt.Errorf("the taken course is already attributed to the program %s", programCode)
+	}
+
+	err = putRecordWithMeta(ctx, owner, strconv.Itoa(studentId), "CourseAttribution", attribution.HashValue, attribution)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UpdateProgramEnrollmentStatus changes the status of a student's program enrollment, e.g. to Graduated or Withdrawn. The enrollment is
+// written as a new version that supersedes the current one, and the intact degree awards of the program are re-sealed over it.
+func (Transcript *SmartContract) UpdateProgramEnrollmentStatus(ctx contractapi.TransactionContextInterface, owner string, studentId int, programCode string,
+	status string, reason string) (bool, error) {
+
+	if !isOneOf(status, enrollmentStatuses) {
+		return false, fmt.Errorf("unknown enrollment status %q, expected one of %v", status, enrollmentStatuses)
+	}
+
+	if reason == "" {
+		return false, fmt.Errorf("the reason of the status change must not be empty")
+	}
+
+	enrollment, meta, err := Transcript.getStudentProgramEnrollmentEntry(ctx, owner, strconv.Itoa(studentId), programCode)
+	if err != nil {
+		return false, fmt.Errorf("%v", err)
+	}
+
+	if enrollment == nil {
+		return false, fmt.Errorf("the student %d is not enrolled to the program %s", studentId, programCode)
+	}
+
+	if enrollment.Status == status {
+		return false, fmt.Errorf("the enrollment of the student %d to the program %s is already %s", studentId, programCode, status)
+	}
+
+	enrollment.Status = status
+	enrollment.HashValue = ""
+	enrollment.HashValue = StructToMD5(*enrollment)
+
+	_, err = supersedeRecord(ctx, meta, enrollment.HashValue, *enrollment, reason)
+	if err != nil {
+		return false, err
+	}
+
+	// The program transcript is read as it was before the change, which is not visible to the transaction that writes it
+	_, err = Transcript.resealDegreeAwards(ctx, owner, strconv.Itoa(studentId), fmt.Sprintf("Enrollment status: %s", status), func(transcript *StudentTranscript) {
+		if transcript.Program != nil && transcript.Program.ProgramCode == programCode {
+			transcript.Program = enrollment
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (Transcript *SmartContract) Get_Student_ProgramEnrollments(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*ProgramEnrollment, error) {
+	var enrollments []*ProgramEnrollment
+
+	records, err := getStudentRecordEntries(ctx, hei, "ProgramEnrollment", studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var enrollment ProgramEnrollment
+		err = decodeRecord(ctx, record, &enrollment)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch program enrollment record by hash value: %v", err)
+		}
+
+		enrollments = append(enrollments, &enrollment)
+	}
+
+	return enrollments, nil
+}
+
+// GetStudentProgramTranscript constructs a transcript that lists only the taken courses attributed to one of the student's programs
+func (Transcript *SmartContract) GetStudentProgramTranscript(ctx contractapi.TransactionContextInterface, hei string, studentID string, programCode string) (*StudentTranscript, error) {
+	enrollment, err := Transcript.getStudentProgramEnrollment(ctx, hei, studentID, programCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	if enrollment == nil {
+		return nil, fmt.Errorf("the student %s is not enrolled to the program %s", studentID, programCode)
+	}
+
+	attributed, err := getProgramAttributedCourses(ctx, hei, studentID, programCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	transcript, err := Transcript.buildStudentTranscript(ctx, hei, studentID, func(hashValue string) bool { return attributed[hashValue] })
+	if err != nil {
+		return nil, err
+	}
+
+	transcript.Program = enrollment
+
+	return transcript, nil
+}
+
+func (Transcript *SmartContract) getStudentProgramEnrollment(ctx contractapi.TransactionContextInterface, hei string, studentID string, programCode string) (*ProgramEnrollment, error) {
+	enrollment, _, err := Transcript.getStudentProgramEnrollmentEntry(ctx, hei, studentID, programCode)
+	return enrollment, err
+}
+
+// getStudentProgramEnrollmentEntry returns the student's current enrollment to the program with its MetaInfo record, or nil
+func (Transcript *SmartContract) getStudentProgramEnrollmentEntry(ctx contractapi.TransactionContextInterface, hei string, studentID string,
+	programCode string) (*ProgramEnrollment, *MetaInfo, error) {
+
+	records, err := getStudentRecordEntries(ctx, hei, "ProgramEnrollment", studentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var enrollment ProgramEnrollment
+		err = decodeRecord(ctx, record, &enrollment)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error during fetch program enrollment record by hash value: %v", err)
+		}
+
+		if enrollment.ProgramCode == programCode {
+			return &enrollment, &record.MetaInfo, nil
+		}
+	}
+
+	return nil, nil, nil
+}
+
+// getProgramAttributedCourses returns the hash values of the TakenCourse records attributed to the given program
+func getProgramAttributedCourses(ctx contractapi.TransactionContextInterface, hei string, studentID string, programCode string) (map[string]bool, error) {
+	attributed := make(map[string]bool)
+
+	records, err := getStudentRecordEntries(ctx, hei, "CourseAttribution", studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		var attribution CourseAttribution
+		err = decodeRecord(ctx, record, &attribution)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch course attribution record by hash value: %v", err)
+		}
+
+		if attribution.ProgramCode == programCode {
+			attributed[attribution.TakenCourseHash] = true
+		}
+	}
+
+	return attributed, nil
+}
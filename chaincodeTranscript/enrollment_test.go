@@ -0,0 +1,111 @@
+package chaincodeTranscript
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestAttributeTakenCourseToProgramChecksTheOwner(t *testing.T) {
+	ledger := awardedLedger(t)
+
+	courses, err := ledger.contract.Get_Student_TakenCourses(ledger.ctx(), testHEI, "190908809")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Hash values are global, so another HEI can name a record it does not own
+	takenCourseHash := courses[0].HashValue
+
+	tests := []struct {
+		name    string
+		owner   string
+		wantErr string
+	}{
+		{name: "owner", owner: testHEI},
+		{name: "another HEI", owner: "Istanbul University", wantErr: "is not a record of Istanbul University"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ledger.trySubmit(func(ctx contractapi.TransactionContextInterface) error {
+				return errorOf(ledger.contract.AttributeTakenCourseToProgram(ctx, test.owner, 190908809, takenCourseHash, "CENG-BSc"))
+			})
+
+			checkError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestUpdateProgramEnrollmentStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		reason  string
+		wantErr string
+	}{
+		{name: "graduated", status: "Graduated", reason: "Completed the program"},
+		{name: "withdrawn", status: "Withdrawn", reason: "Withdrew from the program"},
+		{name: "unknown status", status: "Expelled", reason: "Disciplinary decision", wantErr: "unknown enrollment status"},
+		{name: "same status", status: "Active", reason: "No change", wantErr: "is already Active"},
+		{name: "no reason", status: "Graduated", wantErr: "reason of the status change must not be empty"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := awardedLedger(t)
+
+			err := ledger.trySubmit(func(ctx contractapi.TransactionContextInterface) error {
+				return errorOf(ledger.contract.UpdateProgramEnrollmentStatus(ctx, testHEI, 190908809, "CENG-BSc", test.status, test.reason))
+			})
+			if checkError(t, err, test.wantErr) {
+				return
+			}
+
+			enrollment, err := ledger.contract.getStudentProgramEnrollment(ledger.ctx(), testHEI, "190908809", "CENG-BSc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if enrollment == nil || enrollment.Status != test.status {
+				t.Fatalf("got the enrollment %+v, want the status %s", enrollment, test.status)
+			}
+
+			// The program award is re-sealed over the new status, the others are left as they are
+			for diploma, intact := range map[string]bool{"FBU-1": true, "FBU-2": true, "FBU-3": false} {
+				verification, err := ledger.contract.VerifyDegreeAward(ledger.ctx(), testHEI, diploma)
+				if err != nil {
+					t.Fatalf("VerifyDegreeAward(%s): %v", diploma, err)
+				}
+				if verification.Intact != intact {
+					t.Errorf("VerifyDegreeAward(%s).Intact = %v, want %v", diploma, verification.Intact, intact)
+				}
+			}
+		})
+	}
+}
+
+func TestInsertNewRecordProgramEnrollmentNeedsAStudentInfo(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+
+	tests := []struct {
+		name      string
+		studentID int
+		wantErr   string
+	}{
+		{name: "student with a student info", studentID: 190908809},
+		{name: "student without a student info", studentID: 190908810, wantErr: "there is not a student info record of the student 190908810"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ledger.trySubmit(func(ctx contractapi.TransactionContextInterface) error {
+				return errorOf(ledger.contract.InsertNewRecordProgramEnrollment(ctx, testHEI, test.studentID, "CENG-BSc",
+					"Faculty of Engineering and Architecture", "Department of Computer Engineering", "Undergraduate", "Major", "Major / OSYM",
+					"2022-09-02", "Active"))
+			})
+
+			checkError(t, err, test.wantErr)
+		})
+	}
+}
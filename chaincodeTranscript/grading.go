@@ -0,0 +1,357 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Grading scales and grade point averages: each HEI registers its own scales, versioned by effective date
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// GradeDefinition gives the meaning of one grade of a grading scale
+type GradeDefinition struct {
+	Code        string  `json:"code"`
+	Coefficient float64 `json:"coefficient"` // Multiplied by the course weight (credit or ECTS) to give the grade points
+	Passing     bool    `json:"passing"`
+	CountsInGPA bool    `json:"counts_in_gpa"` // The course weight is included in the GPA denominator
+	MinScore    float64 `json:"min_score"`     // Numeric score range the grade stands for, both ends included; 0-0 for a grade without a range
+	MaxScore    float64 `json:"max_score"`
+}
+
+// GradingScale is one version of a grading scale of an HEI, stored under the "gradingScale" composite key. A version is in force
+// from its EffectiveFrom date until the next version of the same scale.
+type GradingScale struct {
+	Owner         string            `json:"owner"`
+	ScaleID       string            `json:"scale_id"`
+	Name          string            `json:"name"`
+	EffectiveFrom string            `json:"effective_from"` // ISO-8601 date, YYYY-MM-DD
+	Numeric       bool              `json:"numeric"`        // Grades are recorded as scores, e.g. 87, and resolved by the score ranges
+	Grades        []GradeDefinition `json:"grades"`
+}
+
+// defaultGradingScale is the AA-FF scale used by HEIs that have not selected a scale of their own; S/U and EX are passed or failed without affecting the GPA
+var defaultGradingScale = GradingScale{ScaleID: "AA-FF", Name: "Letter grades AA-FF", EffectiveFrom: "0001-01-01", Grades: []GradeDefinition{
+	{Code: "AA", Coefficient: 4.0, Passing: true, CountsInGPA: true, MinScore: 90, MaxScore: 100},
+	{Code: "BA", Coefficient: 3.5, Passing: true, CountsInGPA: true, MinScore: 85, MaxScore: 89},
+	{Code: "BB", Coefficient: 3.0, Passing: true, CountsInGPA: true, MinScore: 80, MaxScore: 84},
+	{Code: "CB", Coefficient: 2.5, Passing: true, CountsInGPA: true, MinScore: 75, MaxScore: 79},
+	{Code: "CC", Coefficient: 2.0, Passing: true, CountsInGPA: true, MinScore: 70, MaxScore: 74},
+	{Code: "DC", Coefficient: 1.5, Passing: true, CountsInGPA: true, MinScore: 65, MaxScore: 69},
+	{Code: "DD", Coefficient: 1.0, Passing: true, CountsInGPA: true, MinScore: 60, MaxScore: 64},
+	{Code: "FD", Coefficient: 0.5, Passing: false, CountsInGPA: true, MinScore: 50, MaxScore: 59},
+	{Code: "FF", Coefficient: 0.0, Passing: false, CountsInGPA: true, MinScore: 0, MaxScore: 49},
+	{Code: "NA", Coefficient: 0.0, Passing: false, CountsInGPA: true}, // Failed due to non-attendance
+	{Code: "S", Coefficient: 0.0, Passing: true, CountsInGPA: false},  // Satisfactory
+	{Code: "U", Coefficient: 0.0, Passing: false, CountsInGPA: false}, // Unsatisfactory
+	{Code: "EX", Coefficient: 0.0, Passing: true, CountsInGPA: false}, // Exempted
+}}
+
+func (Transcript *SmartContract) RegisterGradingScale(ctx contractapi.TransactionContextInterface, owner string, scaleID string, name string,
+	effectiveFrom string, numeric bool, grades []GradeDefinition) (bool, error) {
+
+	scale := GradingScale{Owner: owner, ScaleID: scaleID, Name: name, Numeric: numeric, Grades: grades}
+
+	if scaleID == "" || len(grades) == 0 {
+		return false, fmt.Errorf("scale id and grades of the grading scale must not be empty")
+	}
+
+	var err error
+	scale.EffectiveFrom, err = normalizeDate(effectiveFrom)
+	if err != nil {
+		return false, err
+	}
+
+	err = scale.validate()
+	if err != nil {
+		return false, err
+	}
+
+	scaleKey, err := ctx.GetStub().CreateCompositeKey("gradingScale", []string{owner, scaleID, scale.EffectiveFrom})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonData, err := ctx.GetStub().GetState(scaleKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if jsonData != nil {
+		return false, fmt.Errorf("the grading scale %s effective from %s exists", scaleID, scale.EffectiveFrom)
+	}
+
+	jsonScale, err := json.Marshal(scale)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(scaleKey, jsonScale)
+	if err != nil {
+		return false, fmt.Errorf("failed to put grading scale to world state. %v", err)
+	}
+
+	return true, nil
+}
+
+// SetGradingScale selects the grading scale that the HEI's grades are validated against and its averages are computed with
+func (Transcript *SmartContract) SetGradingScale(ctx contractapi.TransactionContextInterface, owner string, scaleID string) (bool, error) {
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	config.GradingScale = scaleID
+
+	// The scale must already be in force, after which it stays in force
+	_, err = loadGradingScales(ctx, config)
+	if err != nil {
+		return false, err
+	}
+
+	return true, putHEIConfig(ctx, config)
+}
+
+func (Transcript *SmartContract) Get_HEI_GradingScales(ctx contractapi.TransactionContextInterface, hei string) ([]*GradingScale, error) {
+	var scales []*GradingScale
+
+	err := getByPartialCompositeKey(ctx, "gradingScale", []string{hei}, func(value []byte) error {
+		var scale GradingScale
+		err := json.Unmarshal(value, &scale)
+		if err != nil {
+			return err
+		}
+		scales = append(scales, &scale)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scales) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return scales, nil
+}
+
+// Get_HEI_GradingScaleInForce returns the version of the HEI's selected grading scale in force at the time of the transaction
+func (Transcript *SmartContract) Get_HEI_GradingScaleInForce(ctx contractapi.TransactionContextInterface, hei string) (*GradingScale, error) {
+	config, err := loadHEIConfig(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	scales, err := loadGradingScales(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return scales.inForce(), nil
+}
+
+// gradeLookup is the grading scale in force followed by the scales that were in force before it, newest first, and the default scale.
+// A grade takes its meaning from the newest scale that defines it: a grade the scale in force redefines is read with its new meaning,
+// even on courses recorded under an earlier scale, while a grade it no longer defines keeps the meaning of the scale that last did.
+type gradeLookup []*GradingScale
+
+// loadGradingScales returns the grade lookup of the HEI at the time of the transaction
+func loadGradingScales(ctx contractapi.TransactionContextInterface, config *HEIConfig) (gradeLookup, error) {
+	today, err := txDate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return loadGradingScalesOn(ctx, currentState{}, config, today)
+}
+
+// loadGradingScalesOn is loadGradingScales for the versions in force on the given date (YYYY-MM-DD), read from the given state
+func loadGradingScalesOn(ctx contractapi.TransactionContextInterface, state stateReader, config *HEIConfig, date string) (gradeLookup, error) {
+	defaultScale := defaultGradingScale
+	defaultScale.Owner = config.Owner
+
+	if config.GradingScale == "" {
+		return gradeLookup{&defaultScale}, nil
+	}
+
+	var scales gradeLookup
+
+	err := state.getByPartialCompositeKey(ctx, "gradingScale", []string{config.Owner, config.GradingScale}, func(value []byte) error {
+		var scale GradingScale
+		err := json.Unmarshal(value, &scale)
+		if err != nil {
+			return err
+		}
+		if scale.EffectiveFrom <= date {
+			scales = append(scales, &scale)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scales) == 0 {
+		return nil, fmt.Errorf("there is not a version of the grading scale %s of %s in force on %s", config.GradingScale, config.Owner, date)
+	}
+
+	sort.Slice(scales, func(i, j int) bool { return scales[i].EffectiveFrom > scales[j].EffectiveFrom })
+
+	return append(scales, &defaultScale), nil
+}
+
+func (scales gradeLookup) inForce() *GradingScale {
+	return scales[0]
+}
+
+// grade looks up a grade in the scale in force and then in the earlier scales, newest first; it returns nil for an unknown grade
+func (scales gradeLookup) grade(code string) *GradeDefinition {
+	for _, scale := range scales {
+		definition := scale.grade(code)
+		if definition != nil {
+			return definition
+		}
+	}
+	return nil
+}
+
+// isPassing reports whether a course taken with the given grade is passed
+func (scales gradeLookup) isPassing(code string) bool {
+	definition := scales.grade(code)
+	return definition != nil && definition.Passing
+}
+
+func (scale *GradingScale) validate() error {
+	codes := make(map[string]bool)
+	var hasRange bool
+
+	for index, definition := range scale.Grades {
+		if definition.Code == "" {
+			return fmt.Errorf("the code of the grade %d must not be empty", index+1)
+		}
+
+		if codes[definition.Code] {
+			return fmt.Errorf("the grade %s is defined more than once", definition.Code)
+		}
+		codes[definition.Code] = true
+
+		if definition.Coefficient < 0 {
+			return fmt.Errorf("the coefficient of the grade %s must not be negative", definition.Code)
+		}
+
+		if definition.MinScore > definition.MaxScore {
+			return fmt.Errorf("the score range of the grade %s is empty: %v-%v", definition.Code, definition.MinScore, definition.MaxScore)
+		}
+
+		hasRange = hasRange || definition.hasRange()
+
+		for _, other := range scale.Grades[:index] {
+			if definition.hasRange() && other.hasRange() && definition.MinScore <= other.MaxScore && other.MinScore <= definition.MaxScore {
+				return fmt.Errorf("the score ranges of the grades %s and %s overlap", other.Code, definition.Code)
+			}
+		}
+	}
+
+	if scale.Numeric && !hasRange {
+		return fmt.Errorf("the numeric grading scale %s must define the score ranges of its grades", scale.ScaleID)
+	}
+
+	return nil
+}
+
+// grade looks up a grade by its code or, on a numeric scale, a score such as 87 by the range it falls in; it returns nil for an unknown
+// grade. The score ranges of a letter scale only document the grades, so a score is not a grade of it.
+func (scale *GradingScale) grade(code string) *GradeDefinition {
+	for index := range scale.Grades {
+		if scale.Grades[index].Code == code {
+			return &scale.Grades[index]
+		}
+	}
+
+	if !scale.Numeric {
+		return nil
+	}
+
+	score, err := strconv.ParseFloat(code, 64)
+	if err != nil {
+		return nil
+	}
+
+	for index := range scale.Grades {
+		definition := &scale.Grades[index]
+		if definition.hasRange() && definition.MinScore <= score && score <= definition.MaxScore {
+			return definition
+		}
+	}
+
+	return nil
+}
+
+func (definition *GradeDefinition) hasRange() bool {
+	return definition.MinScore != 0 || definition.MaxScore != 0
+}
+
+// GPASummary holds the credit and ECTS totals and the grade point average of a term or of all terms
+type GPASummary struct {
+	AttemptedCredits int     `json:"attempted_credits"`
+	EarnedCredits    int     `json:"earned_credits"`
+	GPACredits       int     `json:"gpa_credits"`
+	AttemptedECTS    int     `json:"attempted_ects"`
+	EarnedECTS       int     `json:"earned_ects"`
+	GPAECTS          int     `json:"gpa_ects"`
+	Points           float64 `json:"points"` // Sum of grade coefficient × course weight of the courses counted in the GPA
+	GPA              float64 `json:"gpa"`
+}
+
+// add counts one course in the summary; the course weight for the points is its credit or ECTS depending on weighting
+func (summary *GPASummary) add(course CombinedCourseRecords, weighting string, scales gradeLookup) {
+	definition := scales.grade(course.Grade)
+	if definition == nil {
+		return
+	}
+
+	summary.AttemptedCredits += course.Credit
+	summary.AttemptedECTS += course.ECTS
+
+	if definition.Passing {
+		summary.EarnedCredits += course.Credit
+		summary.EarnedECTS += course.ECTS
+	}
+
+	if definition.CountsInGPA {
+		summary.GPACredits += course.Credit
+		summary.GPAECTS += course.ECTS
+		summary.Points += definition.Coefficient * float64(courseWeight(course, weighting))
+	}
+}
+
+// finish rounds the points and computes the GPA
+func (summary *GPASummary) finish(weighting string) {
+	weight := summary.GPACredits
+	if weighting == weightingECTS {
+		weight = summary.GPAECTS
+	}
+
+	if weight > 0 {
+		summary.GPA = roundTo2(summary.Points / float64(weight))
+	}
+	summary.Points = roundTo2(summary.Points)
+}
+
+func courseWeight(course CombinedCourseRecords, weighting string) int {
+	if weighting == weightingECTS {
+		return course.ECTS
+	}
+	return course.Credit
+}
+
+func roundTo2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
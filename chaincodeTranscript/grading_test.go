@@ -0,0 +1,169 @@
+package chaincodeTranscript
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// percentGrades is a numeric scale whose grades are recorded as scores
+var percentGrades = []GradeDefinition{
+	{Code: "P-HIGH", Coefficient: 4, Passing: true, CountsInGPA: true, MinScore: 85, MaxScore: 100},
+	{Code: "P-LOW", Coefficient: 2, Passing: true, CountsInGPA: true, MinScore: 50, MaxScore: 84.99},
+	{Code: "FAIL", Coefficient: 0, Passing: false, CountsInGPA: true, MinScore: 0, MaxScore: 49.99},
+	{Code: "NA", Coefficient: 0, Passing: false, CountsInGPA: true},
+}
+
+func TestGradingScaleGrade(t *testing.T) {
+	numeric := GradingScale{ScaleID: "PERCENT", Numeric: true, Grades: percentGrades}
+	letter := GradingScale{ScaleID: "LETTER", Grades: percentGrades}
+
+	tests := []struct {
+		name  string
+		scale *GradingScale
+		code  string
+		want  string // Code of the grade found, empty for none
+	}{
+		{name: "letter grade of the default scale", scale: &defaultGradingScale, code: "BB", want: "BB"},
+		{name: "score on the default scale", scale: &defaultGradingScale, code: "87"},
+		{name: "unknown grade", scale: &defaultGradingScale, code: "ZZ"},
+		{name: "score on a numeric scale", scale: &numeric, code: "87", want: "P-HIGH"},
+		{name: "lower end of a range", scale: &numeric, code: "50", want: "P-LOW"},
+		{name: "decimal score", scale: &numeric, code: "49.5", want: "FAIL"},
+		{name: "score out of the ranges", scale: &numeric, code: "120"},
+		{name: "grade without a range on a numeric scale", scale: &numeric, code: "NA", want: "NA"},
+		{name: "score on a letter scale with ranges", scale: &letter, code: "87"},
+		{name: "code on a letter scale with ranges", scale: &letter, code: "P-LOW", want: "P-LOW"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got string
+			if definition := test.scale.grade(test.code); definition != nil {
+				got = definition.Code
+			}
+
+			if got != test.want {
+				t.Errorf("grade(%q) = %q, want %q", test.code, got, test.want)
+			}
+		})
+	}
+}
+
+// A grade the scale in force redefines is read with its new meaning, and a grade it dropped with the meaning of the scale that last defined it
+func TestGradeLookupRedefinedGrade(t *testing.T) {
+	earlier := GradingScale{ScaleID: "PERCENT", EffectiveFrom: "2023-09-01", Grades: percentGrades}
+	inForce := GradingScale{ScaleID: "PERCENT", EffectiveFrom: "2024-09-01", Grades: []GradeDefinition{
+		{Code: "P-HIGH", Coefficient: 4, Passing: true, CountsInGPA: true},
+		{Code: "P-LOW", Coefficient: 1.5, Passing: false, CountsInGPA: true},
+	}}
+	scales := gradeLookup{&inForce, &earlier, &defaultGradingScale}
+
+	tests := []struct {
+		code            string
+		wantCoefficient float64
+		wantPassing     bool
+	}{
+		{code: "P-LOW", wantCoefficient: 1.5, wantPassing: false},
+		{code: "P-HIGH", wantCoefficient: 4, wantPassing: true},
+		{code: "FAIL", wantCoefficient: 0, wantPassing: false},
+		{code: "BB", wantCoefficient: 3, wantPassing: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code, func(t *testing.T) {
+			definition := scales.grade(test.code)
+			if definition == nil {
+				t.Fatalf("grade(%q) = nil", test.code)
+			}
+
+			if definition.Coefficient != test.wantCoefficient || scales.isPassing(test.code) != test.wantPassing {
+				t.Errorf("grade(%q) has the coefficient %v and passing %v, want %v and %v", test.code, definition.Coefficient,
+					scales.isPassing(test.code), test.wantCoefficient, test.wantPassing)
+			}
+		})
+	}
+}
+
+func TestGradingScaleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		scale   GradingScale
+		wantErr string
+	}{
+		{name: "default scale", scale: defaultGradingScale},
+		{name: "numeric scale", scale: GradingScale{ScaleID: "PERCENT", Numeric: true, Grades: percentGrades}},
+		{name: "empty code", scale: GradingScale{Grades: []GradeDefinition{{Coefficient: 1}}}, wantErr: "must not be empty"},
+		{name: "duplicate code", scale: GradingScale{Grades: []GradeDefinition{{Code: "A"}, {Code: "A"}}}, wantErr: "defined more than once"},
+		{name: "negative coefficient", scale: GradingScale{Grades: []GradeDefinition{{Code: "A", Coefficient: -1}}}, wantErr: "must not be negative"},
+		{name: "empty range", scale: GradingScale{Grades: []GradeDefinition{{Code: "A", MinScore: 90, MaxScore: 80}}}, wantErr: "is empty"},
+		{
+			name:    "overlapping ranges",
+			scale:   GradingScale{Grades: []GradeDefinition{{Code: "A", MinScore: 80, MaxScore: 100}, {Code: "B", MinScore: 70, MaxScore: 80}}},
+			wantErr: "overlap",
+		},
+		{
+			name:    "numeric scale without ranges",
+			scale:   GradingScale{ScaleID: "PASS-FAIL", Numeric: true, Grades: []GradeDefinition{{Code: "P", Passing: true}, {Code: "F"}}},
+			wantErr: "must define the score ranges",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.scale.validate()
+
+			checkError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestInsertTakenCourseGradedOnTheScaleInForce(t *testing.T) {
+	tests := []struct {
+		name    string
+		numeric bool
+		grade   string
+		wantErr string
+	}{
+		{name: "score on a numeric scale", numeric: true, grade: "87"},
+		{name: "code on a numeric scale", numeric: true, grade: "NA"},
+		{name: "score out of the ranges of a numeric scale", numeric: true, grade: "101", wantErr: "is not defined by the grading scale"},
+		{name: "score on a letter scale", grade: "87", wantErr: "is not defined by the grading scale"},
+		{name: "code on a letter scale", grade: "P-HIGH"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.addGradingScale("2023-09-01", test.numeric, percentGrades)
+
+			err := ledger.trySubmit(func(ctx contractapi.TransactionContextInterface) error {
+				return errorOf(ledger.contract.InsertNewRecordTakenCourse(ctx, testHEI, 190908809, "COMP2004", test.grade, "0", 4))
+			})
+
+			checkError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestLoadGradingScalesRejectsACorruptVersion(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.addGradingScale("2023-09-01", true, percentGrades)
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		key, err := ctx.GetStub().CreateCompositeKey("gradingScale", []string{testHEI, "PERCENT", "2023-10-01"})
+		if err != nil {
+			return err
+		}
+		return ctx.GetStub().PutState(key, []byte(`{"scale_id":`))
+	})
+
+	config, err := loadHEIConfig(ledger.ctx(), testHEI)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = loadGradingScalesOn(ledger.ctx(), currentState{}, config, "2024-01-01")
+	if err == nil {
+		t.Fatal("got no error for a corrupt grading scale version")
+	}
+}
@@ -0,0 +1,142 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Honor lists: "Onur" (Honor) and "Yüksek Onur" (High Honor) status awarded per term
+// *
+// ------------------------------------------------------------------------------------------------------
+
+const (
+	honorStatus     = "Honor"      // Onur
+	highHonorStatus = "High Honor" // Yüksek Onur
+)
+
+// HonorRules decide the honor status of a term; they are part of the HEI configuration
+type HonorRules struct {
+	HonorMinGPA     float64 `json:"honor_min_gpa"`      // Lowest term GPA of the Honor status
+	HighHonorMinGPA float64 `json:"high_honor_min_gpa"` // Lowest term GPA of the High Honor status
+	MinLoad         int     `json:"min_load"`           // Lowest course load of the term, in credits or ECTS following the HEI's GPA weighting
+	NoFailedCourses bool    `json:"no_failed_courses"`  // A failed course in the term rules the status out
+}
+
+// defaultHonorRules are the thresholds of the YÖK regulation: a term GPA of 3.00 for Honor and 3.50 for High Honor without a failed course
+var defaultHonorRules = HonorRules{HonorMinGPA: 3.00, HighHonorMinGPA: 3.50, MinLoad: 0, NoFailedCourses: true}
+
+// HonorStudent is one entry of the honor list of a department for a term
+type HonorStudent struct {
+	StudentID      int     `json:"student_id"`
+	StudentSurname string  `json:"student_surname"`
+	StudentName    string  `json:"student_name"`
+	Semester       int     `json:"semester"`
+	GPA            float64 `json:"gpa"`
+	Honor          string  `json:"honor"`
+}
+
+func (Transcript *SmartContract) SetHonorRules(ctx contractapi.TransactionContextInterface, owner string, honorMinGPA float64, highHonorMinGPA float64,
+	minLoad int, noFailedCourses bool) (bool, error) {
+
+	if honorMinGPA <= 0 || highHonorMinGPA < honorMinGPA {
+		return false, fmt.Errorf("the honor GPA must be positive and not above the high honor GPA: %v, %v", honorMinGPA, highHonorMinGPA)
+	}
+
+	if minLoad < 0 {
+		return false, fmt.Errorf("the minimum course load must not be negative: %d", minLoad)
+	}
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	config.Honors = &HonorRules{HonorMinGPA: honorMinGPA, HighHonorMinGPA: highHonorMinGPA, MinLoad: minLoad, NoFailedCourses: noFailedCourses}
+
+	return true, putHEIConfig(ctx, config)
+}
+
+// GetHonorStudents lists the students of a department with Honor or High Honor status in an academic term, e.g. 2023-2024 Fall.
+// The department is given by its registered code or by its name. Only courses taken in a course offering carry an academic term.
+func (Transcript *SmartContract) GetHonorStudents(ctx contractapi.TransactionContextInterface, hei string, department string, term string) ([]*HonorStudent, error) {
+	var honorStudents []*HonorStudent
+
+	organization, err := loadOrganization(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	inDepartment := organization.departmentFilter(department)
+
+	students, err := Transcript.Get_HEI_StudentInfos(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[int]bool)
+
+	for _, student := range students {
+		if listed[student.StudentID] || !inDepartment(student.DepartmentCode, student.Department) {
+			continue
+		}
+
+		listed[student.StudentID] = true
+
+		transcript, err := Transcript.buildStudentTranscript(ctx, hei, strconv.Itoa(student.StudentID), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, block := range transcript.Terms {
+			if block.Term != term || block.Honor == "" {
+				continue
+			}
+
+			honorStudents = append(honorStudents, &HonorStudent{StudentID: student.StudentID, StudentSurname: transcript.InfoStudent.StudentSurname,
+				StudentName: transcript.InfoStudent.StudentName, Semester: block.Semester, GPA: block.Totals.GPA, Honor: block.Honor})
+		}
+	}
+
+	if len(honorStudents) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	sort.SliceStable(honorStudents, func(i, j int) bool { return honorStudents[i].GPA > honorStudents[j].GPA })
+
+	return honorStudents, nil
+}
+
+// honorOf evaluates the honor status of a term block whose totals are finished; it returns an empty string for no status
+func (rules *HonorRules) honorOf(block *TermBlock, weighting string, scales gradeLookup) string {
+	load := block.Totals.AttemptedCredits
+	if weighting == weightingECTS {
+		load = block.Totals.AttemptedECTS
+	}
+
+	if load == 0 || load < rules.MinLoad {
+		return ""
+	}
+
+	if rules.NoFailedCourses {
+		for _, course := range block.Courses {
+			definition := scales.grade(course.Grade)
+			if definition != nil && !definition.Passing {
+				return ""
+			}
+		}
+	}
+
+	switch {
+	case block.Totals.GPA >= rules.HighHonorMinGPA:
+		return highHonorStatus
+	case block.Totals.GPA >= rules.HonorMinGPA:
+		return honorStatus
+	}
+
+	return ""
+}
@@ -0,0 +1,235 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * MetaInfo keys: every lookup can be served by a composite key range scan, so the chaincode runs on LevelDB as well as CouchDB
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// A MetaInfo record is written under two composite keys, both over (owner, student ID, hash value):
+//   - "heiID", which holds the records of all relations of a student and is read for the history of the records
+//   - "<Relation>Meta", e.g. "TakenCourseMeta", which holds the records of one relation and is read for the lookups by relation. Its
+//     value embeds the record the MetaInfo indexes, so one range scan returns full records instead of a hash value to read per record.
+
+// RelationKeyMigration reports what MigrateRelationKeys wrote
+type RelationKeyMigration struct {
+	Written        int `json:"written"`         // MetaInfo records given their relation key, or given the record in it
+	AlreadyPresent int `json:"already_present"` // MetaInfo records whose relation key already held the record
+}
+
+// recordEntry is the value under a relation key: a MetaInfo record with the record it indexes. Entries written before the records
+// were embedded, and the values under the "heiID" key, have no record.
+type recordEntry struct {
+	MetaInfo
+	Record json.RawMessage `json:"record,omitempty"`
+}
+
+// relationKeyType is the composite key type of the MetaInfo records of a relation
+func relationKeyType(relation string) string {
+	return relation + "Meta"
+}
+
+// putMetaInfo writes a MetaInfo record under its "heiID" composite key, and under its relation key together with the JSON of the record
+// it indexes
+func putMetaInfo(ctx contractapi.TransactionContextInterface, meta MetaInfo, record []byte) error {
+	jsonMeta, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	jsonEntry, err := json.Marshal(recordEntry{MetaInfo: meta, Record: record})
+	if err != nil {
+		return fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	keys := []struct {
+		objectType string
+		value      []byte
+	}{{"heiID", jsonMeta}, {relationKeyType(meta.Relation), jsonEntry}}
+
+	for _, key := range keys {
+		compositeKey, err := ctx.GetStub().CreateCompositeKey(key.objectType, []string{meta.Owner, meta.StudentID, meta.HashValue})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %v", err)
+		}
+
+		err = ctx.GetStub().PutState(compositeKey, key.value)
+		if err != nil {
+			return fmt.Errorf("failed to put meta %s record to world state. %v", meta.Relation, err)
+		}
+	}
+
+	return nil
+}
+
+// updateMetaInfo writes a changed MetaInfo record of a record stored by an earlier transaction, which it reads to embed
+func updateMetaInfo(ctx contractapi.TransactionContextInterface, meta MetaInfo) error {
+	record, err := ctx.GetStub().GetState(meta.HashValue)
+	if err != nil {
+		return fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if record == nil {
+		return fmt.Errorf("there is not a record with the given hash value: %v", meta.HashValue)
+	}
+
+	return putMetaInfo(ctx, meta, record)
+}
+
+// getStudentMetaInfos returns the MetaInfo records of a student for the given relation; unlike the Get_Student_*_HashValues queries it does not fail when there are none
+func getStudentMetaInfos(ctx contractapi.TransactionContextInterface, hei string, relation string, studentID string) ([]*MetaInfo, error) {
+	return getMetaInfos(ctx, relationLookup(relation, hei, studentID))
+}
+
+// getHEIMetaInfos returns the MetaInfo records of all students of an HEI for the given relation, or none
+func getHEIMetaInfos(ctx contractapi.TransactionContextInterface, hei string, relation string) ([]*MetaInfo, error) {
+	return getMetaInfos(ctx, relationLookup(relation, hei))
+}
+
+// getMetaInfos returns the MetaInfo records of the lookup
+func getMetaInfos(ctx contractapi.TransactionContextInterface, search lookup) ([]*MetaInfo, error) {
+	var records []*MetaInfo
+
+	err := iterateLookup(ctx, search, func(value []byte) error {
+		var record MetaInfo
+		err := json.Unmarshal(value, &record)
+		if err != nil {
+			return err
+		}
+
+		records = append(records, &record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// getStudentRecordEntries returns a student's MetaInfo records of the given relation with the records they index
+func getStudentRecordEntries(ctx contractapi.TransactionContextInterface, hei string, relation string, studentID string) ([]*recordEntry, error) {
+	return getRecordEntries(ctx, relationLookup(relation, hei, studentID))
+}
+
+// getHEIRecordEntries returns the MetaInfo records of all students of an HEI for the given relation with the records they index
+func getHEIRecordEntries(ctx contractapi.TransactionContextInterface, hei string, relation string) ([]*recordEntry, error) {
+	return getRecordEntries(ctx, relationLookup(relation, hei))
+}
+
+// getRecordEntries returns the entries of the lookup
+func getRecordEntries(ctx contractapi.TransactionContextInterface, search lookup) ([]*recordEntry, error) {
+	var entries []*recordEntry
+
+	err := iterateLookup(ctx, search, func(value []byte) error {
+		var entry recordEntry
+		err := json.Unmarshal(value, &entry)
+		if err != nil {
+			return err
+		}
+
+		entries = append(entries, &entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// decodeRecord reads the record of an entry into the given struct pointer; the record of an entry written before the records were
+// embedded is read under its hash value
+func decodeRecord(ctx contractapi.TransactionContextInterface, entry *recordEntry, record interface{}) error {
+	if len(entry.Record) == 0 {
+		return getRecordByHashValue(ctx, entry.HashValue, record)
+	}
+
+	err := json.Unmarshal(entry.Record, record)
+	if err != nil {
+		return fmt.Errorf("failed to fetch json data to struct : %v", err)
+	}
+
+	return nil
+}
+
+// currentRecordEntry picks the entry of the current version among the entries of one student and relation, as currentMetaInfo does
+func currentRecordEntry(entries []*recordEntry) *recordEntry {
+	records := make([]*MetaInfo, len(entries))
+	for index := range entries {
+		records[index] = &entries[index].MetaInfo
+	}
+
+	current := currentMetaInfo(records)
+	for index := range entries {
+		if records[index] == current {
+			return entries[index]
+		}
+	}
+
+	return nil
+}
+
+// isCurrent selects the MetaInfo records that are not superseded by a later version
+func isCurrent(record *MetaInfo) bool {
+	return record.SupersededBy == ""
+}
+
+// isFlaggedCurrent selects the current MetaInfo records that carry flags
+func isFlaggedCurrent(record *MetaInfo) bool {
+	return isCurrent(record) && len(record.Flags) > 0
+}
+
+// MigrateRelationKeys writes the relation key of each of the HEI's MetaInfo records stored before the relation keys were introduced,
+// without which the lookups by relation do not find them, and embeds the record in the relation keys written before the records were
+// embedded. Running it again writes nothing.
+func (Transcript *SmartContract) MigrateRelationKeys(ctx contractapi.TransactionContextInterface, owner string) (*RelationKeyMigration, error) {
+	var migration RelationKeyMigration
+
+	// Every MetaInfo record of the HEI, which no selector of a shipped index covers, so the range is scanned on CouchDB as well
+	records, err := getMetaInfos(ctx, lookup{objectType: "heiID", attributes: []string{owner}})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		relationKey, err := ctx.GetStub().CreateCompositeKey(relationKeyType(record.Relation), []string{record.Owner, record.StudentID, record.HashValue})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite key: %v", err)
+		}
+
+		jsonData, err := ctx.GetStub().GetState(relationKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
+		}
+
+		if jsonData != nil {
+			var entry recordEntry
+			err = json.Unmarshal(jsonData, &entry)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+			}
+
+			if len(entry.Record) > 0 {
+				migration.AlreadyPresent++
+				continue
+			}
+		}
+
+		err = updateMetaInfo(ctx, *record)
+		if err != nil {
+			return nil, err
+		}
+
+		migration.Written++
+	}
+
+	return &migration, nil
+}
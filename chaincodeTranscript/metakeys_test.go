@@ -0,0 +1,114 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// readsLedger holds students with a StudentInfo, and courses CourseInfo and TakenCourse records each. With legacy set, the relation keys
+// hold no record, as the entries written before the records were embedded.
+func readsLedger(tb testing.TB, students int, courses int, legacy bool) *testLedger {
+	ledger := newTestLedger(tb)
+
+	for student := 0; student < students; student++ {
+		studentID := 190908800 + student
+		ledger.addStudent(studentID, "Selvi", "Department of Computer Engineering", "2022-09-02")
+
+		for course := 0; course < courses; course++ {
+			courseCode := fmt.Sprintf("COMP%d", 1001+course)
+			ledger.addCourse(studentID, courseCode, "BB", "9", 1+course%8)
+		}
+	}
+
+	if legacy {
+		for key, value := range ledger.stub.state {
+			objectType, _, _ := ledger.stub.SplitCompositeKey(key)
+			if !strings.HasSuffix(objectType, "Meta") {
+				continue
+			}
+
+			var entry recordEntry
+			if err := json.Unmarshal(value, &entry); err != nil {
+				tb.Fatal(err)
+			}
+			ledger.stub.state[key], _ = json.Marshal(entry.MetaInfo)
+		}
+	}
+
+	return ledger
+}
+
+// listReads are the list queries whose records are read from the entries under the relation keys
+var listReads = []struct {
+	name  string
+	query func(contract *SmartContract, ctx contractapi.TransactionContextInterface) error
+}{
+	{name: "GetStudentTranscript", query: func(contract *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return errorOf(contract.GetStudentTranscript(ctx, testHEI, "190908800"))
+	}},
+	{name: "Get_HEI_TakenCourses", query: func(contract *SmartContract, ctx contractapi.TransactionContextInterface) error {
+		return errorOf(contract.Get_HEI_TakenCourses(ctx, testHEI))
+	}},
+}
+
+// countReads runs a query and returns the state reads and range scans it made
+func countReads(tb testing.TB, ledger *testLedger, query func(contract *SmartContract, ctx contractapi.TransactionContextInterface) error) (int, int) {
+	tb.Helper()
+
+	stateReads, rangeScans := ledger.stub.stateReads, ledger.stub.rangeScans
+
+	err := query(ledger.contract, ledger.ctx())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return ledger.stub.stateReads - stateReads, ledger.stub.rangeScans - rangeScans
+}
+
+// The records embedded in the relation key entries are read with the range scans of the list, whatever their number, while the entries
+// written before they were embedded cost one state read per record
+func TestListReadsAreOneRangePass(t *testing.T) {
+	for _, read := range listReads {
+		t.Run(read.name, func(t *testing.T) {
+			fewReads, fewScans := countReads(t, readsLedger(t, 2, 1, false), read.query)
+			manyReads, manyScans := countReads(t, readsLedger(t, 2, 6, false), read.query)
+
+			if manyReads != fewReads || manyScans != fewScans {
+				t.Errorf("got %d state reads and %d range scans for 1 course per student, %d and %d for 6", fewReads, fewScans, manyReads, manyScans)
+			}
+
+			legacyReads, legacyScans := countReads(t, readsLedger(t, 2, 6, true), read.query)
+			if legacyReads <= manyReads || legacyScans != manyScans {
+				t.Errorf("got %d state reads and %d range scans of legacy entries, want more than %d reads and %d scans", legacyReads,
+					legacyScans, manyReads, manyScans)
+			}
+		})
+	}
+}
+
+// BenchmarkListReads reports the state reads and range scans of the list queries, with the records embedded in the relation key entries
+// and with legacy entries
+func BenchmarkListReads(b *testing.B) {
+	for _, read := range listReads {
+		for _, legacy := range []bool{false, true} {
+			b.Run(fmt.Sprintf("%s/legacy=%v", read.name, legacy), func(b *testing.B) {
+				ledger := readsLedger(b, 20, 10, legacy)
+				var stateReads, rangeScans int
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					reads, scans := countReads(b, ledger, read.query)
+					stateReads += reads
+					rangeScans += scans
+				}
+
+				b.ReportMetric(float64(stateReads)/float64(b.N), "reads/op")
+				b.ReportMetric(float64(rangeScans)/float64(b.N), "scans/op")
+			})
+		}
+	}
+}
@@ -0,0 +1,569 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * In-memory world state and ledger for the tests
+// *
+// ------------------------------------------------------------------------------------------------------
+
+const testHEI = "Fenerbahce University"
+
+// mockStub is an in-memory world state. The writes of a transaction are applied when it commits, so that, as on a peer, a transaction
+// does not read its own writes. It counts the state reads and range scans, which the benchmarks report.
+type mockStub struct {
+	shim.ChaincodeStubInterface
+
+	state   map[string][]byte
+	history map[string][]*queryresult.KeyModification // Oldest first
+	writes  map[string]*queryresult.KeyModification   // Writes of the running transaction
+	txCount int
+	txID    string
+	now     time.Time
+	step    time.Duration // Time between two transactions
+
+	stateReads int // GetState calls
+	rangeScans int // Range scans, counting each page
+
+	couchDB bool     // Rich queries are run, as on CouchDB; without it they fail as on LevelDB
+	queries []string // Rich queries run
+}
+
+func newMockStub() *mockStub {
+	return &mockStub{
+		state:   make(map[string][]byte),
+		history: make(map[string][]*queryresult.KeyModification),
+		writes:  make(map[string]*queryresult.KeyModification),
+		now:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		step:    time.Hour,
+	}
+}
+
+// begin starts a transaction
+func (stub *mockStub) begin() {
+	stub.now = stub.now.Add(stub.step)
+	stub.txCount++
+	stub.txID = fmt.Sprintf("tx%04d", stub.txCount)
+	stub.writes = make(map[string]*queryresult.KeyModification)
+}
+
+// commit applies the writes of the running transaction
+func (stub *mockStub) commit() {
+	keys := make([]string, 0, len(stub.writes))
+	for key := range stub.writes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		write := stub.writes[key]
+		if write.IsDelete {
+			delete(stub.state, key)
+		} else {
+			stub.state[key] = write.Value
+		}
+		stub.history[key] = append(stub.history[key], write)
+	}
+
+	stub.writes = make(map[string]*queryresult.KeyModification)
+}
+
+func (stub *mockStub) GetTxID() string {
+	return stub.txID
+}
+
+// GetChannelID names the channel after the state database, since the contract detects the state database once per channel
+func (stub *mockStub) GetChannelID() string {
+	if stub.couchDB {
+		return "couchdb"
+	}
+	return "leveldb"
+}
+
+func (stub *mockStub) GetTxTimestamp() (*timestamppb.Timestamp, error) {
+	return timestamppb.New(stub.now), nil
+}
+
+func (stub *mockStub) GetState(key string) ([]byte, error) {
+	stub.stateReads++
+	return stub.state[key], nil
+}
+
+func (stub *mockStub) PutState(key string, value []byte) error {
+	if key == "" {
+		return fmt.Errorf("key must not be an empty string")
+	}
+
+	stub.writes[key] = &queryresult.KeyModification{TxId: stub.txID, Value: value, Timestamp: timestamppb.New(stub.now)}
+	return nil
+}
+
+func (stub *mockStub) DelState(key string) error {
+	stub.writes[key] = &queryresult.KeyModification{TxId: stub.txID, IsDelete: true, Timestamp: timestamppb.New(stub.now)}
+	return nil
+}
+
+func (stub *mockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return shim.CreateCompositeKey(objectType, attributes)
+}
+
+func (stub *mockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	components := strings.Split(strings.TrimSuffix(strings.TrimPrefix(compositeKey, "\x00"), "\x00"), "\x00")
+	return components[0], components[1:], nil
+}
+
+// keysWithPrefix returns the keys of the state that start with the prefix, in key order as a range scan returns them
+func (stub *mockStub) keysWithPrefix(prefix string) []string {
+	var keys []string
+	for key := range stub.state {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (stub *mockStub) rows(keys []string) []*queryresult.KV {
+	rows := make([]*queryresult.KV, 0, len(keys))
+	for _, key := range keys {
+		rows = append(rows, &queryresult.KV{Key: key, Value: stub.state[key]})
+	}
+
+	return rows
+}
+
+func (stub *mockStub) GetStateByPartialCompositeKey(objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	prefix, err := shim.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	stub.rangeScans++
+	return &mockIterator{rows: stub.rows(stub.keysWithPrefix(prefix))}, nil
+}
+
+// GetStateByPartialCompositeKeyWithPagination pages as a peer does: the bookmark is the key the next page starts at, empty after the last page
+func (stub *mockStub) GetStateByPartialCompositeKeyWithPagination(objectType string, attributes []string, pageSize int32,
+	bookmark string) (shim.StateQueryIteratorInterface, *peer.QueryResponseMetadata, error) {
+
+	prefix, err := shim.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stub.rangeScans++
+
+	keys := stub.keysWithPrefix(prefix)
+	start := sort.SearchStrings(keys, bookmark)
+	end := start + int(pageSize)
+
+	next := ""
+	if end < len(keys) {
+		next = keys[end]
+	} else {
+		end = len(keys)
+	}
+
+	metadata := peer.QueryResponseMetadata{FetchedRecordsCount: int32(end - start), Bookmark: next}
+	return &mockIterator{rows: stub.rows(keys[start:end])}, &metadata, nil
+}
+
+// GetQueryResult runs a Mango query over the world state on CouchDB, returning the matching rows in key order
+func (stub *mockStub) GetQueryResult(query string) (shim.StateQueryIteratorInterface, error) {
+	keys, err := stub.runQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mockIterator{rows: stub.rows(keys)}, nil
+}
+
+// GetQueryResultWithPagination pages a Mango query as GetStateByPartialCompositeKeyWithPagination pages a range
+func (stub *mockStub) GetQueryResultWithPagination(query string, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface,
+	*peer.QueryResponseMetadata, error) {
+
+	keys, err := stub.runQuery(query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	start := sort.SearchStrings(keys, bookmark)
+	end := start + int(pageSize)
+
+	next := ""
+	if end < len(keys) {
+		next = keys[end]
+	} else {
+		end = len(keys)
+	}
+
+	metadata := peer.QueryResponseMetadata{FetchedRecordsCount: int32(end - start), Bookmark: next}
+	return &mockIterator{rows: stub.rows(keys[start:end])}, &metadata, nil
+}
+
+// runQuery returns the keys of the documents the query's selector matches, in key order
+func (stub *mockStub) runQuery(query string) ([]string, error) {
+	if !stub.couchDB {
+		return nil, fmt.Errorf("ExecuteQuery not supported for leveldb")
+	}
+
+	stub.queries = append(stub.queries, query)
+
+	var parsed struct {
+		Selector map[string]interface{} `json:"selector"`
+	}
+	err := json.Unmarshal([]byte(query), &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query %s: %v", query, err)
+	}
+
+	var keys []string
+	for key, value := range stub.state {
+		var document map[string]interface{}
+		if json.Unmarshal(value, &document) != nil {
+			continue
+		}
+
+		document["_id"] = key
+		if matchesSelector(document, parsed.Selector) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// matchesSelector evaluates the subset of the Mango selector syntax the contract uses
+func matchesSelector(document map[string]interface{}, match map[string]interface{}) bool {
+	for field, condition := range match {
+		switch field {
+		case "$and", "$or":
+			var any bool
+			for _, sub := range condition.([]interface{}) {
+				matched := matchesSelector(document, sub.(map[string]interface{}))
+				if field == "$and" && !matched {
+					return false
+				}
+				any = any || matched
+			}
+			if field == "$or" && !any {
+				return false
+			}
+		default:
+			value, present := document[field]
+			if !matchesCondition(value, present, condition) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func matchesCondition(value interface{}, present bool, condition interface{}) bool {
+	operators, ok := condition.(map[string]interface{})
+	if !ok {
+		return present && reflect.DeepEqual(value, condition)
+	}
+
+	for operator, operand := range operators {
+		var matched bool
+		switch operator {
+		case "$exists":
+			matched = present == operand.(bool)
+		case "$in":
+			for _, candidate := range operand.([]interface{}) {
+				matched = matched || (present && reflect.DeepEqual(value, candidate))
+			}
+		case "$gt", "$gte", "$lt", "$lte":
+			matched = present && compareOrdered(value, operand, operator)
+		case "$regex":
+			text, isString := value.(string)
+			matched = present && isString && regexp.MustCompile(operand.(string)).MatchString(text)
+		default:
+			panic("unsupported Mango operator " + operator)
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func compareOrdered(value interface{}, operand interface{}, operator string) bool {
+	var comparison int
+	switch typed := value.(type) {
+	case string:
+		other, ok := operand.(string)
+		if !ok {
+			return false
+		}
+		comparison = strings.Compare(typed, other)
+	case float64:
+		other, ok := operand.(float64)
+		if !ok {
+			return false
+		}
+		if typed < other {
+			comparison = -1
+		} else if typed > other {
+			comparison = 1
+		}
+	default:
+		return false
+	}
+
+	switch operator {
+	case "$gt":
+		return comparison > 0
+	case "$gte":
+		return comparison >= 0
+	case "$lt":
+		return comparison < 0
+	}
+	return comparison <= 0
+}
+
+// GetHistoryForKey returns the committed writes of a key, newest first as a peer does
+func (stub *mockStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	var modifications []*queryresult.KeyModification
+	for index := len(stub.history[key]) - 1; index >= 0; index-- {
+		modifications = append(modifications, stub.history[key][index])
+	}
+
+	return &mockHistoryIterator{modifications: modifications}, nil
+}
+
+type mockIterator struct {
+	rows []*queryresult.KV
+}
+
+func (iterator *mockIterator) HasNext() bool {
+	return len(iterator.rows) > 0
+}
+
+func (iterator *mockIterator) Next() (*queryresult.KV, error) {
+	if len(iterator.rows) == 0 {
+		return nil, fmt.Errorf("no more rows")
+	}
+
+	row := iterator.rows[0]
+	iterator.rows = iterator.rows[1:]
+	return row, nil
+}
+
+func (iterator *mockIterator) Close() error {
+	return nil
+}
+
+type mockHistoryIterator struct {
+	modifications []*queryresult.KeyModification
+}
+
+func (iterator *mockHistoryIterator) HasNext() bool {
+	return len(iterator.modifications) > 0
+}
+
+func (iterator *mockHistoryIterator) Next() (*queryresult.KeyModification, error) {
+	if len(iterator.modifications) == 0 {
+		return nil, fmt.Errorf("no more modifications")
+	}
+
+	modification := iterator.modifications[0]
+	iterator.modifications = iterator.modifications[1:]
+	return modification, nil
+}
+
+func (iterator *mockHistoryIterator) Close() error {
+	return nil
+}
+
+// testLedger runs the contract over a mockStub
+type testLedger struct {
+	t        testing.TB
+	stub     *mockStub
+	contract *SmartContract
+}
+
+func newTestLedger(t testing.TB) *testLedger {
+	return &testLedger{t: t, stub: newMockStub(), contract: new(SmartContract)}
+}
+
+// ctx returns a transaction context over the world state, for queries
+func (ledger *testLedger) ctx() contractapi.TransactionContextInterface {
+	ctx := new(contractapi.TransactionContext)
+	ctx.SetStub(ledger.stub)
+	return ctx
+}
+
+// trySubmit runs fn as a transaction, commits its writes when it succeeds and returns its error
+func (ledger *testLedger) trySubmit(fn func(ctx contractapi.TransactionContextInterface) error) error {
+	ledger.stub.begin()
+
+	err := fn(ledger.ctx())
+	if err == nil {
+		ledger.stub.commit()
+	}
+
+	return err
+}
+
+// submit runs fn as a transaction and commits its writes; the test fails when it returns an error
+func (ledger *testLedger) submit(fn func(ctx contractapi.TransactionContextInterface) error) {
+	ledger.t.Helper()
+
+	err := ledger.trySubmit(fn)
+	if err != nil {
+		ledger.t.Fatalf("transaction failed: %v", err)
+	}
+}
+
+// errorOf drops the result of a transaction, keeping its error
+func errorOf[T any](_ T, err error) error {
+	return err
+}
+
+// addStudent inserts a StudentInfo record registered in the given department
+func (ledger *testLedger) addStudent(studentID int, surname string, department string, registrationDate string) {
+	ledger.t.Helper()
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.InsertNewRecordStudentInfo(ctx, testHEI, "Faculty of Engineering and Architecture", department, studentID,
+			surname, "Test", "10000000000", registrationDate, "Major / OSYM", "Undergraduate", 0, 0))
+	})
+}
+
+// addCourseInfo inserts a CourseInfo record of a student
+func (ledger *testLedger) addCourseInfo(studentID int, courseCode string, ects int, credit int) {
+	ledger.t.Helper()
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.InsertNewRecordCourseInfo(ctx, testHEI, studentID, courseCode, "Course "+courseCode, "C", ects, credit))
+	})
+}
+
+// addTakenCourse inserts a TakenCourse record of a student
+func (ledger *testLedger) addTakenCourse(studentID int, courseCode string, grade string, point string, semester int) {
+	ledger.t.Helper()
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.InsertNewRecordTakenCourse(ctx, testHEI, studentID, courseCode, grade, point, semester))
+	})
+}
+
+// addCourse inserts a CourseInfo record of a course of 6 ECTS and 3 credits, and then a TakenCourse record of it
+func (ledger *testLedger) addCourse(studentID int, courseCode string, grade string, point string, semester int) {
+	ledger.t.Helper()
+
+	ledger.addCourseInfo(studentID, courseCode, 6, 3)
+	ledger.addTakenCourse(studentID, courseCode, grade, point, semester)
+}
+
+// updateStudentInfo amends the StudentInfo record of a student registered as addStudent registers them, with the Selvi surname
+func (ledger *testLedger) updateStudentInfo(studentID int, department string, name string, class int, reason string) error {
+	return ledger.trySubmit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.UpdateStudentInfo(ctx, testHEI, studentID, "Faculty of Engineering and Architecture", department, "Selvi", name,
+			"10000000000", "2022-09-02", "Major / OSYM", "Undergraduate", class, 0, reason))
+	})
+}
+
+// addFaculty registers the Faculty of Engineering and Architecture under the code FEA
+func (ledger *testLedger) addFaculty() {
+	ledger.t.Helper()
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.RegisterFaculty(ctx, testHEI, "FEA", "Faculty of Engineering and Architecture", []string{}))
+	})
+}
+
+// addDepartment registers a department of the faculty FEA, which addFaculty registers
+func (ledger *testLedger) addDepartment(code string, name string, coursePrefixes ...string) {
+	ledger.t.Helper()
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.RegisterDepartment(ctx, testHEI, code, "FEA", name, []string{}, coursePrefixes))
+	})
+}
+
+// addGradingScale registers a version of the grading scale PERCENT and selects the scale for the HEI
+func (ledger *testLedger) addGradingScale(effectiveFrom string, numeric bool, grades []GradeDefinition) {
+	ledger.t.Helper()
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.RegisterGradingScale(ctx, testHEI, "PERCENT", "Percent scores", effectiveFrom, numeric, grades))
+	})
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.SetGradingScale(ctx, testHEI, "PERCENT"))
+	})
+}
+
+// putLegacyRecord stores a record as an earlier version of the chaincode did, without the validation and conversion of the insert transactions
+func (ledger *testLedger) putLegacyRecord(studentID int, relation string, hashValue string, record interface{}) {
+	ledger.t.Helper()
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return putRecordWithMeta(ctx, testHEI, fmt.Sprint(studentID), relation, hashValue, record)
+	})
+}
+
+// checkError fails the test when err is not the error wanted, a nil error when wantErr is empty; it returns whether an error was wanted,
+// which ends the test case
+func checkError(t testing.TB, err error, wantErr string) bool {
+	t.Helper()
+
+	if wantErr == "" {
+		if err != nil {
+			t.Fatalf("got the error %v", err)
+		}
+		return false
+	}
+
+	if err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Fatalf("got the error %v, want %q", err, wantErr)
+	}
+	return true
+}
+
+// allPages follows the bookmarks of a paginated query from its first page to its last one, which has no bookmark, and returns the records
+// of every page
+func allPages[T any](t testing.TB, fetch func(bookmark string) ([]T, string, error)) []T {
+	t.Helper()
+
+	var records []T
+	bookmark := ""
+	for pages := 0; pages == 0 || bookmark != ""; pages++ {
+		if pages > 50 {
+			t.Fatal("the bookmark never gets empty")
+		}
+
+		page, next, err := fetch(bookmark)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, page...)
+		bookmark = next
+	}
+
+	return records
+}
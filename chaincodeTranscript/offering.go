@@ -0,0 +1,163 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Course offerings: a course opened in a term and section, with who taught it, in which language and how
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// CourseOffering is registered once per (course, term, section) of an HEI under the "offering" composite key
+type CourseOffering struct {
+	CourseCode     string `json:"course_code"`
+	Term           string `json:"term"`    // Academic term, e.g. 2023-2024 Fall
+	Section        string `json:"section"` // Section number within the term
+	InstructorID   string `json:"instructor_id"`
+	InstructorName string `json:"instructor_name"`
+	Language       string `json:"language"`      // Language of instruction
+	DeliveryMode   string `json:"delivery_mode"` // Face-to-face, Online, Hybrid
+	HashValue      string `json:"hash_value"`
+}
+
+var deliveryModes = []string{"Face-to-face", "Online", "Hybrid"}
+
+func (Transcript *SmartContract) InsertNewRecordCourseOffering(ctx contractapi.TransactionContextInterface, owner string, courseCode string, term string, section string,
+	instructorID string, instructorName string, language string, deliveryMode string) (bool, error) {
+
+	var offering CourseOffering
+
+	if courseCode == "" || term == "" {
+		return false, fmt.Errorf("course code and term of the offering must not be empty")
+	}
+
+	if !isOneOf(deliveryMode, deliveryModes) {
+		return false, fmt.Errorf("unknown delivery mode %q, expected one of %v", deliveryMode, deliveryModes)
+	}
+
+	offeringKey, err := ctx.GetStub().CreateCompositeKey("offering", []string{owner, courseCode, term, section})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonData, err := ctx.GetStub().GetState(offeringKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if jsonData != nil {
+		return false, fmt.Errorf("the course offering %s %s section %s exists", courseCode, term, section)
+	}
+
+	offering.CourseCode = courseCode
+	offering.Term = term
+	offering.Section = section
+	offering.InstructorID = instructorID
+	offering.InstructorName = instructorName
+	offering.Language = language
+	offering.DeliveryMode = deliveryMode
+
+	offering.HashValue = StructToMD5(offering)
+
+	jsonOffering, err := json.Marshal(offering)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(offeringKey, jsonOffering)
+	if err != nil {
+		return false, fmt.Errorf("failed to put course offering to world state. %v", err)
+	}
+
+	return true, nil
+}
+
+// InsertNewRecordTakenCourseInOffering records a taken course that references the offering (term and section) it was taken in
+func (Transcript *SmartContract) InsertNewRecordTakenCourseInOffering(ctx contractapi.TransactionContextInterface, owner string, studentId int,
+	courseCode string, grade string, point string, takenSemester int, term string, section string) (bool, error) {
+
+	var course TakenCourse
+
+	_, err := Transcript.Get_CourseOffering(ctx, owner, courseCode, term, section)
+	if err != nil {
+		return false, fmt.Errorf("%v", err)
+	}
+
+	course.StudentID = studentId
+	course.CourseCode = courseCode
+	course.Grade = grade
+	course.Point, err = parseDecimal(point)
+	if err != nil {
+		return false, err
+	}
+	course.TakenSemester = takenSemester
+	course.Term = term
+	course.Section = section
+
+	return Transcript.insertTakenCourse(ctx, owner, course)
+}
+
+func (Transcript *SmartContract) Get_CourseOffering(ctx contractapi.TransactionContextInterface, hei string, courseCode string, term string, section string) (*CourseOffering, error) {
+	return getCourseOffering(ctx, currentState{}, hei, courseCode, term, section)
+}
+
+// getCourseOffering reads a course offering from the given state
+func getCourseOffering(ctx contractapi.TransactionContextInterface, state stateReader, hei string, courseCode string, term string, section string) (*CourseOffering, error) {
+	offeringKey, err := ctx.GetStub().CreateCompositeKey("offering", []string{hei, courseCode, term, section})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonData, err := state.getState(ctx, offeringKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if jsonData == nil {
+		return nil, fmt.Errorf("there is not a course offering %s %s section %s", courseCode, term, section)
+	}
+
+	var offering CourseOffering
+	err = json.Unmarshal(jsonData, &offering)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+	}
+
+	return &offering, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_CourseOfferings(ctx contractapi.TransactionContextInterface, hei string) ([]*CourseOffering, error) {
+	var offerings []*CourseOffering
+
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey("offering", []string{hei})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryRow, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+		var offering CourseOffering
+		err = json.Unmarshal(queryRow.Value, &offering)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+		}
+
+		offerings = append(offerings, &offering)
+	}
+
+	if len(offerings) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return offerings, nil
+}
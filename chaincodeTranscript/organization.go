@@ -0,0 +1,417 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Faculty and department hierarchy of an HEI, registered with stable codes
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// Faculty is registered once per HEI under the "faculty" composite key
+type Faculty struct {
+	Code    string   `json:"code"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"` // Other spellings of the name found in existing records
+}
+
+// Department is registered once per HEI under the "department" composite key and belongs to a Faculty
+type Department struct {
+	Code           string   `json:"code"`
+	FacultyCode    string   `json:"faculty_code"`
+	Name           string   `json:"name"`
+	Aliases        []string `json:"aliases"`         // Other spellings of the name found in existing records, e.g. "Computer Engineering"
+	CoursePrefixes []string `json:"course_prefixes"` // Course code prefixes of the courses the department offers, e.g. COMP
+}
+
+// DepartmentCodeMigration reports what MigrateDepartmentCodes changed and which records it could not map
+type DepartmentCodeMigration struct {
+	StudentInfosUpdated  int      `json:"student_infos_updated"`
+	CourseInfosUpdated   int      `json:"course_infos_updated"`
+	DegreeAwardsResealed int      `json:"degree_awards_resealed"` // Awards whose transcript hash was re-sealed over the mapped StudentInfo records
+	Unresolved           []string `json:"unresolved"`
+}
+
+func (Transcript *SmartContract) RegisterFaculty(ctx contractapi.TransactionContextInterface, owner string, code string, name string, aliases []string) (bool, error) {
+	faculty := Faculty{Code: code, Name: name, Aliases: aliases}
+
+	if code == "" || name == "" {
+		return false, fmt.Errorf("code and name of the faculty must not be empty")
+	}
+
+	if faculty.Aliases == nil {
+		faculty.Aliases = []string{}
+	}
+
+	organization, err := loadOrganization(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	for _, existing := range organization.faculties {
+		if existing.Code == code {
+			return false, fmt.Errorf("the faculty %s exists", code)
+		}
+		for _, spelling := range append([]string{name}, aliases...) {
+			if existing.isNamed(spelling) {
+				return false, fmt.Errorf("the name %q is already used by the faculty %s", spelling, existing.Code)
+			}
+		}
+	}
+
+	return true, putOrganizationUnit(ctx, "faculty", owner, code, faculty)
+}
+
+func (Transcript *SmartContract) RegisterDepartment(ctx contractapi.TransactionContextInterface, owner string, code string, facultyCode string, name string,
+	aliases []string, coursePrefixes []string) (bool, error) {
+
+	department := Department{Code: code, FacultyCode: facultyCode, Name: name, Aliases: aliases, CoursePrefixes: coursePrefixes}
+
+	if code == "" || name == "" {
+		return false, fmt.Errorf("code and name of the department must not be empty")
+	}
+
+	if department.Aliases == nil {
+		department.Aliases = []string{}
+	}
+
+	if department.CoursePrefixes == nil {
+		department.CoursePrefixes = []string{}
+	}
+
+	organization, err := loadOrganization(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	if organization.faculty(facultyCode) == nil {
+		return false, fmt.Errorf("there is not a registered faculty with the code %s", facultyCode)
+	}
+
+	for _, existing := range organization.departments {
+		if existing.Code == code {
+			return false, fmt.Errorf("the department %s exists", code)
+		}
+		for _, spelling := range append([]string{name}, aliases...) {
+			if existing.isNamed(spelling) {
+				return false, fmt.Errorf("the name %q is already used by the department %s", spelling, existing.Code)
+			}
+		}
+		for _, prefix := range coursePrefixes {
+			if isOneOf(prefix, existing.CoursePrefixes) {
+				return false, fmt.Errorf("the course prefix %s is already used by the department %s", prefix, existing.Code)
+			}
+		}
+	}
+
+	return true, putOrganizationUnit(ctx, "department", owner, code, department)
+}
+
+func (Transcript *SmartContract) Get_HEI_Faculties(ctx contractapi.TransactionContextInterface, hei string) ([]*Faculty, error) {
+	organization, err := loadOrganization(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(organization.faculties) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return organization.faculties, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_Departments(ctx contractapi.TransactionContextInterface, hei string) ([]*Department, error) {
+	organization, err := loadOrganization(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(organization.departments) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return organization.departments, nil
+}
+
+// MigrateDepartmentCodes maps the free-text faculty and department names of the HEI's current StudentInfo records, and the
+// course code prefixes of its CourseInfo records, to the registered codes. Each changed record is written as a new version. The degree
+// awards certifying a mapped StudentInfo record are re-sealed over it, unless their transcript already changed since the award.
+func (Transcript *SmartContract) MigrateDepartmentCodes(ctx contractapi.TransactionContextInterface, owner string) (*DepartmentCodeMigration, error) {
+	migration := DepartmentCodeMigration{Unresolved: []string{}}
+	reason := "Migration: faculty and department codes"
+
+	var students []StudentInfo
+
+	organization, err := loadOrganization(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(organization.departments) == 0 {
+		return nil, fmt.Errorf("there are not any registered departments of %s to migrate to", owner)
+	}
+
+	for _, relation := range []string{"StudentInfo", "CourseInfo"} {
+		records, err := getHEIRecordEntries(ctx, owner, relation)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			if record.SupersededBy != "" {
+				continue
+			}
+
+			if relation == "StudentInfo" {
+				var student StudentInfo
+				err = decodeRecord(ctx, record, &student)
+				if err != nil {
+					return nil, fmt.Errorf("error during fetch student info record by hash value: %v", err)
+				}
+
+				if student.DepartmentCode != "" {
+					continue
+				}
+
+				student.FacultyCode, student.DepartmentCode, err = organization.resolveStudentUnits(student.Faculty, student.Department)
+				if err != nil || student.DepartmentCode == "" {
+					migration.Unresolved = append(migration.Unresolved, fmt.Sprintf("StudentInfo %s of the student %s: %q / %q", record.HashValue, record.StudentID, student.Faculty, student.Department))
+					continue
+				}
+
+				student.HashValue = ""
+				student.HashValue = StructToMD5(student)
+
+				_, err = supersedeRecord(ctx, &record.MetaInfo, student.HashValue, student, reason)
+				if err != nil {
+					return nil, err
+				}
+				students = append(students, student)
+				migration.StudentInfosUpdated++
+			} else {
+				var course CourseInfo
+				err = decodeRecord(ctx, record, &course)
+				if err != nil {
+					return nil, fmt.Errorf("error during fetch course info record by hash value: %v", err)
+				}
+
+				if course.DepartmentCode != "" {
+					continue
+				}
+
+				course.DepartmentCode = organization.resolveCourseDepartment(course.CourseCode)
+				if course.DepartmentCode == "" {
+					migration.Unresolved = append(migration.Unresolved, fmt.Sprintf("CourseInfo %s of the student %s: %s", record.HashValue, record.StudentID, course.CourseCode))
+					continue
+				}
+
+				course.HashValue = ""
+				course.HashValue = StructToMD5(course)
+
+				_, err = supersedeRecord(ctx, &record.MetaInfo, course.HashValue, course, reason)
+				if err != nil {
+					return nil, err
+				}
+				migration.CourseInfosUpdated++
+			}
+		}
+	}
+
+	// The transcripts are read as they were before this migration, which is not visible to the transaction that writes it
+	for _, student := range students {
+		resealed, err := Transcript.resealDegreeAwards(ctx, owner, strconv.Itoa(student.StudentID), reason, func(transcript *StudentTranscript) {
+			transcript.InfoStudent = student
+		})
+		if err != nil {
+			return nil, err
+		}
+		migration.DegreeAwardsResealed += resealed
+	}
+
+	return &migration, nil
+}
+
+// organization holds the registered faculties and departments of one HEI
+type organization struct {
+	faculties   []*Faculty
+	departments []*Department
+}
+
+func loadOrganization(ctx contractapi.TransactionContextInterface, hei string) (*organization, error) {
+	var org organization
+
+	for _, objectType := range []string{"faculty", "department"} {
+		iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, []string{hei})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
+		}
+
+		for iterator.HasNext() {
+			queryRow, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
+			}
+
+			if objectType == "faculty" {
+				var faculty Faculty
+				err = json.Unmarshal(queryRow.Value, &faculty)
+				org.faculties = append(org.faculties, &faculty)
+			} else {
+				var department Department
+				err = json.Unmarshal(queryRow.Value, &department)
+				org.departments = append(org.departments, &department)
+			}
+
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+			}
+		}
+
+		iterator.Close()
+	}
+
+	return &org, nil
+}
+
+// departmentFilter matches the department of a record, given by its code and name, against a department given by its registered code
+// or by its name; while the department is not registered only the name is compared
+func (org *organization) departmentFilter(department string) func(code string, name string) bool {
+	for _, registered := range org.departments {
+		if registered.isNamed(department) {
+			return func(code string, name string) bool {
+				return code == registered.Code || registered.isNamed(name)
+			}
+		}
+	}
+
+	return func(code string, name string) bool {
+		return name == department
+	}
+}
+
+// facultyFilter is departmentFilter for faculties
+func (org *organization) facultyFilter(faculty string) func(code string, name string) bool {
+	for _, registered := range org.faculties {
+		if registered.isNamed(faculty) {
+			return func(code string, name string) bool {
+				return code == registered.Code || registered.isNamed(name)
+			}
+		}
+	}
+
+	return func(code string, name string) bool {
+		return name == faculty
+	}
+}
+
+func (org *organization) faculty(code string) *Faculty {
+	for _, faculty := range org.faculties {
+		if faculty.Code == code {
+			return faculty
+		}
+	}
+	return nil
+}
+
+// resolveStudentUnits maps free-text faculty and department names to registered codes. While the HEI has not registered any
+// departments the names are accepted as they are and the codes stay empty.
+func (org *organization) resolveStudentUnits(facultyName string, departmentName string) (string, string, error) {
+	if len(org.departments) == 0 {
+		return "", "", nil
+	}
+
+	var department *Department
+	for _, candidate := range org.departments {
+		if candidate.isNamed(departmentName) {
+			department = candidate
+			break
+		}
+	}
+
+	if department == nil {
+		return "", "", fmt.Errorf("the department %q is not registered for the HEI", departmentName)
+	}
+
+	faculty := org.faculty(department.FacultyCode)
+	if faculty == nil || !faculty.isNamed(facultyName) {
+		return "", "", fmt.Errorf("the department %s does not belong to the faculty %q", department.Code, facultyName)
+	}
+
+	return faculty.Code, department.Code, nil
+}
+
+// resolveCourseDepartment returns the code of the department owning the longest course code prefix matching courseCode, or an empty string
+func (org *organization) resolveCourseDepartment(courseCode string) string {
+	var code string
+	var longest int
+
+	for _, department := range org.departments {
+		for _, prefix := range department.CoursePrefixes {
+			if strings.HasPrefix(courseCode, prefix) && len(prefix) > longest {
+				code = department.Code
+				longest = len(prefix)
+			}
+		}
+	}
+
+	return code
+}
+
+func (faculty *Faculty) isNamed(name string) bool {
+	return unitNameMatches(name, faculty.Code, faculty.Name, faculty.Aliases)
+}
+
+func (department *Department) isNamed(name string) bool {
+	return unitNameMatches(name, department.Code, department.Name, department.Aliases)
+}
+
+func unitNameMatches(name string, code string, unitName string, aliases []string) bool {
+	if name == code {
+		return true
+	}
+
+	normalized := normalizeUnitName(name)
+	for _, spelling := range append([]string{unitName}, aliases...) {
+		if normalizeUnitName(spelling) == normalized {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeUnitName makes "Department of Computer Engineering" and "computer  engineering" compare equal
+func normalizeUnitName(name string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	normalized = strings.TrimPrefix(normalized, "department of ")
+	normalized = strings.TrimPrefix(normalized, "faculty of ")
+	return normalized
+}
+
+func putOrganizationUnit(ctx contractapi.TransactionContextInterface, objectType string, owner string, code string, unit interface{}) error {
+	unitKey, err := ctx.GetStub().CreateCompositeKey(objectType, []string{owner, code})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonUnit, err := json.Marshal(unit)
+	if err != nil {
+		return fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(unitKey, jsonUnit)
+	if err != nil {
+		return fmt.Errorf("failed to put %s to world state. %v", objectType, err)
+	}
+
+	return nil
+}
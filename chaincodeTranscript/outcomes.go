@@ -0,0 +1,261 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Learning outcomes of a program and the competencies a student developed through passed courses
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// LearningOutcome is a program outcome (competency) registered under the "outcome" composite key
+type LearningOutcome struct {
+	ProgramCode string `json:"program_code"`
+	Code        string `json:"code"` // e.g. PO1
+	Description string `json:"description"`
+}
+
+// OutcomeMapping states how much a course, identified by its CourseInfo course code, contributes to a learning outcome
+type OutcomeMapping struct {
+	ProgramCode       string `json:"program_code"`
+	OutcomeCode       string `json:"outcome_code"`
+	CourseCode        string `json:"course_code"`
+	ContributionLevel int    `json:"contribution_level"` // 1 (low) to 5 (high)
+}
+
+// CompetencyScore aggregates the contribution of a student's passed courses to one learning outcome
+type CompetencyScore struct {
+	OutcomeCode          string   `json:"outcome_code"`
+	Description          string   `json:"description"`
+	AchievedContribution int      `json:"achieved_contribution"` // Sum of the contribution levels of the passed mapped courses
+	PossibleContribution int      `json:"possible_contribution"` // Sum of the contribution levels of all mapped courses
+	Ratio                float64  `json:"ratio"`                 // AchievedContribution / PossibleContribution
+	PassedCourses        []string `json:"passed_courses"`
+}
+
+// CompetencyProfile lists the competency scores of a student for every learning outcome of a program
+type CompetencyProfile struct {
+	StudentID   string            `json:"student_id"`
+	ProgramCode string            `json:"program_code"`
+	Outcomes    []CompetencyScore `json:"outcomes"`
+}
+
+const minContributionLevel, maxContributionLevel = 1, 5
+
+func (Transcript *SmartContract) DefineLearningOutcome(ctx contractapi.TransactionContextInterface, owner string, programCode string, code string, description string) (bool, error) {
+	outcome := LearningOutcome{ProgramCode: programCode, Code: code, Description: description}
+
+	if programCode == "" || code == "" {
+		return false, fmt.Errorf("program code and outcome code must not be empty")
+	}
+
+	outcomeKey, err := ctx.GetStub().CreateCompositeKey("outcome", []string{owner, programCode, code})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonData, err := ctx.GetStub().GetState(outcomeKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if jsonData != nil {
+		return false, fmt.Errorf("the learning outcome %s of the program %s exists", code, programCode)
+	}
+
+	jsonOutcome, err := json.Marshal(outcome)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(outcomeKey, jsonOutcome)
+	if err != nil {
+		return false, fmt.Errorf("failed to put learning outcome to world state. %v", err)
+	}
+
+	return true, nil
+}
+
+// MapCourseToOutcome sets the contribution level of a course to a learning outcome; mapping the same pair again replaces the level
+func (Transcript *SmartContract) MapCourseToOutcome(ctx contractapi.TransactionContextInterface, owner string, programCode string, outcomeCode string, courseCode string, contributionLevel int) (bool, error) {
+	mapping := OutcomeMapping{ProgramCode: programCode, OutcomeCode: outcomeCode, CourseCode: courseCode, ContributionLevel: contributionLevel}
+
+	if contributionLevel < minContributionLevel || contributionLevel > maxContributionLevel {
+		return false, fmt.Errorf("contribution level must be between %d and %d: %d", minContributionLevel, maxContributionLevel, contributionLevel)
+	}
+
+	outcomeKey, err := ctx.GetStub().CreateCompositeKey("outcome", []string{owner, programCode, outcomeCode})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonData, err := ctx.GetStub().GetState(outcomeKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if jsonData == nil {
+		return false, fmt.Errorf("there is not a learning outcome %s of the program %s", outcomeCode, programCode)
+	}
+
+	mappingKey, err := ctx.GetStub().CreateCompositeKey("outcomeMapping", []string{owner, programCode, courseCode, outcomeCode})
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonMapping, err := json.Marshal(mapping)
+	if err != nil {
+		return false, fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(mappingKey, jsonMapping)
+	if err != nil {
+		return false, fmt.Errorf("failed to put outcome mapping to world state. %v", err)
+	}
+
+	return true, nil
+}
+
+func (Transcript *SmartContract) Get_Program_LearningOutcomes(ctx contractapi.TransactionContextInterface, hei string, programCode string) ([]*LearningOutcome, error) {
+	var outcomes []*LearningOutcome
+
+	err := getByPartialCompositeKey(ctx, "outcome", []string{hei, programCode}, func(value []byte) error {
+		var outcome LearningOutcome
+		err := json.Unmarshal(value, &outcome)
+		outcomes = append(outcomes, &outcome)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(outcomes) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return outcomes, nil
+}
+
+func (Transcript *SmartContract) Get_Program_OutcomeMappings(ctx contractapi.TransactionContextInterface, hei string, programCode string) ([]*OutcomeMapping, error) {
+	var mappings []*OutcomeMapping
+
+	err := getByPartialCompositeKey(ctx, "outcomeMapping", []string{hei, programCode}, func(value []byte) error {
+		var mapping OutcomeMapping
+		err := json.Unmarshal(value, &mapping)
+		mappings = append(mappings, &mapping)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(mappings) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return mappings, nil
+}
+
+// GetStudentCompetencyProfile aggregates the student's passed courses into a score per learning outcome of the program
+func (Transcript *SmartContract) GetStudentCompetencyProfile(ctx contractapi.TransactionContextInterface, hei string, studentID string, programCode string) (*CompetencyProfile, error) {
+	profile := CompetencyProfile{StudentID: studentID, ProgramCode: programCode, Outcomes: []CompetencyScore{}}
+
+	outcomes, err := Transcript.Get_Program_LearningOutcomes(ctx, hei, programCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the learning outcomes of the program %s: %v", programCode, err)
+	}
+
+	mappings, err := Transcript.Get_Program_OutcomeMappings(ctx, hei, programCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the outcome mappings of the program %s: %v", programCode, err)
+	}
+
+	config, err := loadHEIConfig(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	scales, err := loadGradingScales(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// A student without taken courses has a profile with nothing achieved, so the records are read without Get_Student_TakenCourses
+	records, err := getStudentRecordEntries(ctx, hei, "TakenCourse", studentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the taken courses of the student %s: %v", studentID, err)
+	}
+
+	passed := make(map[string]bool)
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var course TakenCourse
+		err = decodeRecord(ctx, record, &course)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
+		}
+
+		if scales.isPassing(course.Grade) {
+			passed[course.CourseCode] = true
+		}
+	}
+
+	for _, outcome := range outcomes {
+		score := CompetencyScore{OutcomeCode: outcome.Code, Description: outcome.Description, PassedCourses: []string{}}
+
+		for _, mapping := range mappings {
+			if mapping.OutcomeCode != outcome.Code {
+				continue
+			}
+
+			score.PossibleContribution += mapping.ContributionLevel
+			if passed[mapping.CourseCode] {
+				score.AchievedContribution += mapping.ContributionLevel
+				score.PassedCourses = append(score.PassedCourses, mapping.CourseCode)
+			}
+		}
+
+		if score.PossibleContribution > 0 {
+			score.Ratio = math.Round(float64(score.AchievedContribution)/float64(score.PossibleContribution)*100) / 100
+		}
+
+		sort.Strings(score.PassedCourses)
+		profile.Outcomes = append(profile.Outcomes, score)
+	}
+
+	return &profile, nil
+}
+
+// getByPartialCompositeKey calls handle with the value of every state entry whose composite key starts with the given attributes
+func getByPartialCompositeKey(ctx contractapi.TransactionContextInterface, objectType string, attributes []string, handle func(value []byte) error) error {
+	iterator, err := ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryRow, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+
+		err = handle(queryRow.Value)
+		if err != nil {
+			return fmt.Errorf("failed to fetch json data to struct : %v", err)
+		}
+	}
+
+	return nil
+}
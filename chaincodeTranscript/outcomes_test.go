@@ -0,0 +1,85 @@
+package chaincodeTranscript
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestGetStudentCompetencyProfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		takenCourses []struct{ code, grade string }
+		wantAchieved map[string]int
+		wantRatios   map[string]float64
+	}{
+		{
+			name:         "no taken courses",
+			wantAchieved: map[string]int{"PO1": 0, "PO2": 0},
+			wantRatios:   map[string]float64{"PO1": 0, "PO2": 0},
+		},
+		{
+			name:         "passed and failed courses",
+			takenCourses: []struct{ code, grade string }{{"COMP1001", "BB"}, {"COMP2004", "FF"}},
+			wantAchieved: map[string]int{"PO1": 5, "PO2": 0},
+			wantRatios:   map[string]float64{"PO1": 0.63, "PO2": 0},
+		},
+		{
+			name:         "repeated course passed at the second attempt",
+			takenCourses: []struct{ code, grade string }{{"COMP2004", "FF"}, {"COMP2004", "CC"}},
+			wantAchieved: map[string]int{"PO1": 3, "PO2": 2},
+			wantRatios:   map[string]float64{"PO1": 0.38, "PO2": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			contract := ledger.contract
+
+			setup := []func(ctx contractapi.TransactionContextInterface) error{
+				func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(contract.DefineLearningOutcome(ctx, testHEI, "CENG-BSc", "PO1", "Apply the knowledge of computing"))
+				},
+				func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(contract.DefineLearningOutcome(ctx, testHEI, "CENG-BSc", "PO2", "Design software systems"))
+				},
+				func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(contract.MapCourseToOutcome(ctx, testHEI, "CENG-BSc", "PO1", "COMP1001", 5))
+				},
+				func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(contract.MapCourseToOutcome(ctx, testHEI, "CENG-BSc", "PO1", "COMP2004", 3))
+				},
+				func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(contract.MapCourseToOutcome(ctx, testHEI, "CENG-BSc", "PO2", "COMP2004", 2))
+				},
+			}
+			for _, transaction := range setup {
+				ledger.submit(transaction)
+			}
+
+			for index, course := range test.takenCourses {
+				ledger.addTakenCourse(190908809, course.code, course.grade, "0", index+1)
+			}
+
+			profile, err := contract.GetStudentCompetencyProfile(ledger.ctx(), testHEI, "190908809", "CENG-BSc")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(profile.Outcomes) != len(test.wantAchieved) {
+				t.Fatalf("got %d outcomes, want %d", len(profile.Outcomes), len(test.wantAchieved))
+			}
+
+			for _, score := range profile.Outcomes {
+				if score.AchievedContribution != test.wantAchieved[score.OutcomeCode] || score.Ratio != test.wantRatios[score.OutcomeCode] {
+					t.Errorf("%s: achieved %d, ratio %v; want %d, %v", score.OutcomeCode, score.AchievedContribution, score.Ratio,
+						test.wantAchieved[score.OutcomeCode], test.wantRatios[score.OutcomeCode])
+				}
+				if score.PassedCourses == nil {
+					t.Errorf("%s: passed courses are nil", score.OutcomeCode)
+				}
+			}
+		})
+	}
+}
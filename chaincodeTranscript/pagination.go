@@ -0,0 +1,492 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Paginated queries: the Get_HEI_* and Get_Student_* list queries one page at a time
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// Each paginated query takes a page size and a bookmark, empty for the first page, and returns the records of the page with the
+// bookmark of the next page and the number of records in the page. A page with fewer records than the page size is the last one.
+// Paginated queries can only be run as queries, not as part of a transaction that writes to the ledger.
+
+// Page is one page of the records of a paginated query. The contract API names the schema of a returned type after the type, which it
+// cannot do for an instantiated generic type, so each query returns a named page type defined on Page.
+type Page[T any] struct {
+	Records      []T    `json:"records"`
+	Bookmark     string `json:"bookmark"`
+	FetchedCount int32  `json:"fetched_count"`
+}
+
+// TakenCoursePage is one page of TakenCourse records
+type TakenCoursePage Page[*TakenCourse]
+
+// CourseInfoPage is one page of CourseInfo records
+type CourseInfoPage Page[*CourseInfo]
+
+// StudentInfoPage is one page of StudentInfo records
+type StudentInfoPage Page[*StudentInfo]
+
+// MetaInfoPage is one page of MetaInfo records
+type MetaInfoPage Page[*MetaInfo]
+
+// HashValuePage is one page of hash values of records
+type HashValuePage Page[string]
+
+// StudentInfoVersionPage is one page of versions of a student's personal data
+type StudentInfoVersionPage Page[*StudentInfoVersion]
+
+// ProgramEnrollmentPage is one page of ProgramEnrollment records
+type ProgramEnrollmentPage Page[*ProgramEnrollment]
+
+// DegreeAwardPage is one page of DegreeAward records
+type DegreeAwardPage Page[*DegreeAward]
+
+// TermRegistrationPage is one page of TermRegistration records
+type TermRegistrationPage Page[*TermRegistration]
+
+// CourseOfferingPage is one page of course offerings
+type CourseOfferingPage Page[*CourseOffering]
+
+// FacultyPage is one page of faculties
+type FacultyPage Page[*Faculty]
+
+// DepartmentPage is one page of departments
+type DepartmentPage Page[*Department]
+
+// GradingScalePage is one page of grading scale versions
+type GradingScalePage Page[*GradingScale]
+
+//------------------------------------------------------------------------------------------------------
+// *
+// * Paginated queries of a higher education institution's (HEI's) records
+// *
+//------------------------------------------------------------------------------------------------------
+
+func (Transcript *SmartContract) Get_HEI_TakenCourses_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*TakenCoursePage, error) {
+	page := TakenCoursePage{Records: []*TakenCourse{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("TakenCourse", hei).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var course TakenCourse
+		err := decodeRecord(ctx, entry, &course)
+		page.Records = append(page.Records, &course)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_CourseInfos_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*CourseInfoPage, error) {
+	page := CourseInfoPage{Records: []*CourseInfo{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("CourseInfo", hei).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var course CourseInfo
+		err := decodeRecord(ctx, entry, &course)
+		page.Records = append(page.Records, &course)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_StudentInfos_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*StudentInfoPage, error) {
+	page := StudentInfoPage{Records: []*StudentInfo{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("StudentInfo", hei).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var student StudentInfo
+		err := decodeRecord(ctx, entry, &student)
+		page.Records = append(page.Records, &student)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_MetaInfos_TakenCourses_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*MetaInfoPage, error) {
+	return getMetaInfosPage(ctx, relationLookup("TakenCourse", hei), pageSize, bookmark)
+}
+
+func (Transcript *SmartContract) Get_HEI_MetaInfos_StudentInfos_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*MetaInfoPage, error) {
+	return getMetaInfosPage(ctx, relationLookup("StudentInfo", hei), pageSize, bookmark)
+}
+
+func (Transcript *SmartContract) Get_HEI_MetaInfos_CourseInfos_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*MetaInfoPage, error) {
+	return getMetaInfosPage(ctx, relationLookup("CourseInfo", hei), pageSize, bookmark)
+}
+
+func (Transcript *SmartContract) Get_HEI_FlaggedRecords_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*MetaInfoPage, error) {
+	return getMetaInfosPage(ctx, flaggedLookup(hei), pageSize, bookmark)
+}
+
+func (Transcript *SmartContract) Get_HEI_CourseOfferings_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*CourseOfferingPage, error) {
+	page := CourseOfferingPage{Records: []*CourseOffering{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getByPartialCompositeKeyPage(ctx, "offering", []string{hei}, pageSize, bookmark, func(value []byte) error {
+		var offering CourseOffering
+		err := json.Unmarshal(value, &offering)
+		page.Records = append(page.Records, &offering)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_Faculties_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*FacultyPage, error) {
+	page := FacultyPage{Records: []*Faculty{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getByPartialCompositeKeyPage(ctx, "faculty", []string{hei}, pageSize, bookmark, func(value []byte) error {
+		var faculty Faculty
+		err := json.Unmarshal(value, &faculty)
+		page.Records = append(page.Records, &faculty)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_Departments_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*DepartmentPage, error) {
+	page := DepartmentPage{Records: []*Department{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getByPartialCompositeKeyPage(ctx, "department", []string{hei}, pageSize, bookmark, func(value []byte) error {
+		var department Department
+		err := json.Unmarshal(value, &department)
+		page.Records = append(page.Records, &department)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_HEI_GradingScales_Paginated(ctx contractapi.TransactionContextInterface, hei string, pageSize int32, bookmark string) (*GradingScalePage, error) {
+	page := GradingScalePage{Records: []*GradingScale{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getByPartialCompositeKeyPage(ctx, "gradingScale", []string{hei}, pageSize, bookmark, func(value []byte) error {
+		var scale GradingScale
+		err := json.Unmarshal(value, &scale)
+		page.Records = append(page.Records, &scale)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+//------------------------------------------------------------------------------------------------------
+// *
+// * Paginated queries of a student's records
+// *
+//------------------------------------------------------------------------------------------------------
+
+func (Transcript *SmartContract) Get_Student_TakenCourses_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*TakenCoursePage, error) {
+	page := TakenCoursePage{Records: []*TakenCourse{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("TakenCourse", hei, studentID).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var course TakenCourse
+		err := decodeRecord(ctx, entry, &course)
+		page.Records = append(page.Records, &course)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_Student_CourseInfos_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*CourseInfoPage, error) {
+	page := CourseInfoPage{Records: []*CourseInfo{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("CourseInfo", hei, studentID).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var course CourseInfo
+		err := decodeRecord(ctx, entry, &course)
+		page.Records = append(page.Records, &course)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_Student_StudentInfo_HashValues_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*HashValuePage, error) {
+	return getHashValuesPage(ctx, relationLookup("StudentInfo", hei, studentID).current(), pageSize, bookmark)
+}
+
+func (Transcript *SmartContract) Get_Student_CourseInfos_HashValues_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*HashValuePage, error) {
+	return getHashValuesPage(ctx, relationLookup("CourseInfo", hei, studentID).current(), pageSize, bookmark)
+}
+
+func (Transcript *SmartContract) Get_Student_TakenCourses_HashValues_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*HashValuePage, error) {
+	return getHashValuesPage(ctx, relationLookup("TakenCourse", hei, studentID).current(), pageSize, bookmark)
+}
+
+// Get_Student_StudentInfo_Versions_Paginated returns a page of the versions of a student's personal data, oldest first. The versions are
+// keyed by the hash of their data rather than by version number, so all of them are read and the bookmark is the hash value of the last
+// version of the page; it is empty on the last page.
+func (Transcript *SmartContract) Get_Student_StudentInfo_Versions_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*StudentInfoVersionPage, error) {
+	page := StudentInfoVersionPage{Records: []*StudentInfoVersion{}}
+
+	err := validatePageSize(pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := studentInfoVersions(ctx, hei, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 0
+	if bookmark != "" {
+		start = -1
+		for index, version := range versions {
+			if version.Record.HashValue == bookmark {
+				start = index + 1
+				break
+			}
+		}
+
+		if start < 0 {
+			return nil, fmt.Errorf("invalid bookmark %q, expected the hash value of a version of the student %s", bookmark, studentID)
+		}
+	}
+
+	end := start + int(pageSize)
+	if end < len(versions) {
+		page.Bookmark = versions[end-1].Record.HashValue
+	} else {
+		end = len(versions)
+	}
+
+	page.Records = append(page.Records, versions[start:end]...)
+	page.FetchedCount = int32(len(page.Records))
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_Student_ProgramEnrollments_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*ProgramEnrollmentPage, error) {
+	page := ProgramEnrollmentPage{Records: []*ProgramEnrollment{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("ProgramEnrollment", hei, studentID).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var enrollment ProgramEnrollment
+		err := decodeRecord(ctx, entry, &enrollment)
+		page.Records = append(page.Records, &enrollment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_Student_DegreeAwards_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*DegreeAwardPage, error) {
+	page := DegreeAwardPage{Records: []*DegreeAward{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("DegreeAward", hei, studentID).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var award DegreeAward
+		err := decodeRecord(ctx, entry, &award)
+		page.Records = append(page.Records, &award)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+func (Transcript *SmartContract) Get_Student_TermRegistrations_Paginated(ctx contractapi.TransactionContextInterface, hei string, studentID string, pageSize int32, bookmark string) (*TermRegistrationPage, error) {
+	page := TermRegistrationPage{Records: []*TermRegistration{}}
+
+	var err error
+	page.Bookmark, page.FetchedCount, err = getRecordsPage(ctx, relationLookup("TermRegistration", hei, studentID).current(), pageSize, bookmark, func(entry *recordEntry) error {
+		var registration TermRegistration
+		err := decodeRecord(ctx, entry, &registration)
+		page.Records = append(page.Records, &registration)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+//------------------------------------------------------------------------------------------------------
+// *
+// * Pagination helpers
+// *
+//------------------------------------------------------------------------------------------------------
+
+func validatePageSize(pageSize int32) error {
+	if pageSize <= 0 {
+		return fmt.Errorf("the page size must be positive, got %d", pageSize)
+	}
+	return nil
+}
+
+// getRecordEntriesPage returns one page of the entries of the lookup, with the bookmark of the next page and the number of entries in the
+// page. The lookup's range or query is read until the page is full, since the records it does not keep are skipped after they are fetched.
+func getRecordEntriesPage(ctx contractapi.TransactionContextInterface, search lookup, pageSize int32, bookmark string) ([]*recordEntry, string, int32, error) {
+	err := validatePageSize(pageSize)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	entries := []*recordEntry{}
+
+	for int32(len(entries)) < pageSize {
+		remaining := pageSize - int32(len(entries))
+
+		nextBookmark, fetchedCount, err := iterateLookupPage(ctx, search, remaining, bookmark, func(value []byte) error {
+			var entry recordEntry
+			err := json.Unmarshal(value, &entry)
+			if err != nil {
+				return err
+			}
+
+			entries = append(entries, &entry)
+			return nil
+		})
+		if err != nil {
+			return nil, "", 0, err
+		}
+
+		bookmark = nextBookmark
+
+		// Fewer rows than asked for, or no next page: the range or query is exhausted. An empty bookmark would restart it from the first page
+		if fetchedCount < remaining || bookmark == "" {
+			break
+		}
+	}
+
+	return entries, bookmark, int32(len(entries)), nil
+}
+
+// getMetaInfosPage returns one page of the MetaInfo records of the lookup
+func getMetaInfosPage(ctx contractapi.TransactionContextInterface, search lookup, pageSize int32, bookmark string) (*MetaInfoPage, error) {
+	page := MetaInfoPage{Records: []*MetaInfo{}}
+
+	entries, nextBookmark, fetchedCount, err := getRecordEntriesPage(ctx, search, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		page.Records = append(page.Records, &entry.MetaInfo)
+	}
+
+	page.Bookmark = nextBookmark
+	page.FetchedCount = fetchedCount
+
+	return &page, nil
+}
+
+// getRecordsPage reads one page of the entries of the lookup and hands each to handle, which decodes its record; it returns the bookmark
+// of the next page and the number of records in the page
+func getRecordsPage(ctx contractapi.TransactionContextInterface, search lookup, pageSize int32, bookmark string,
+	handle func(entry *recordEntry) error) (string, int32, error) {
+
+	entries, nextBookmark, fetchedCount, err := getRecordEntriesPage(ctx, search, pageSize, bookmark)
+	if err != nil {
+		return "", 0, err
+	}
+
+	for _, entry := range entries {
+		err = handle(entry)
+		if err != nil {
+			return "", 0, fmt.Errorf("error during fetch %s record by hash value: %v", entry.Relation, err)
+		}
+	}
+
+	return nextBookmark, fetchedCount, nil
+}
+
+func getHashValuesPage(ctx contractapi.TransactionContextInterface, search lookup, pageSize int32, bookmark string) (*HashValuePage, error) {
+	page := HashValuePage{Records: []string{}}
+
+	records, err := getMetaInfosPage(ctx, search, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records.Records {
+		page.Records = append(page.Records, record.HashValue)
+	}
+
+	page.Bookmark = records.Bookmark
+	page.FetchedCount = records.FetchedCount
+
+	return &page, nil
+}
+
+// getByPartialCompositeKeyPage is getByPartialCompositeKey for one page; it returns the bookmark of the next page and the fetched count
+func getByPartialCompositeKeyPage(ctx contractapi.TransactionContextInterface, objectType string, attributes []string, pageSize int32, bookmark string,
+	handle func(value []byte) error) (string, int32, error) {
+
+	err := validatePageSize(pageSize)
+	if err != nil {
+		return "", 0, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(objectType, attributes, pageSize, bookmark)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryRow, err := iterator.Next()
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+
+		err = handle(queryRow.Value)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to fetch json data to struct : %v", err)
+		}
+	}
+
+	return metadata.GetBookmark(), metadata.GetFetchedRecordsCount(), nil
+}
@@ -0,0 +1,170 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Point check: the point of a taken course is the grade coefficient times the course weight (credit or ECTS)
+// *
+// ------------------------------------------------------------------------------------------------------
+
+const (
+	pointCheckFlag   = "flag"   // The record is stored with a flag on its MetaInfo record
+	pointCheckReject = "reject" // The insert fails
+)
+
+var pointCheckModes = []string{pointCheckFlag, pointCheckReject}
+
+// SetPointCheck selects whether a taken course whose point disagrees with its grade and catalog entry is flagged or rejected
+func (Transcript *SmartContract) SetPointCheck(ctx contractapi.TransactionContextInterface, owner string, mode string) (bool, error) {
+	if !isOneOf(mode, pointCheckModes) {
+		return false, fmt.Errorf("unknown point check mode %q, expected one of %v", mode, pointCheckModes)
+	}
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	config.PointCheck = mode
+
+	return true, putHEIConfig(ctx, config)
+}
+
+// Get_HEI_FlaggedRecords returns the MetaInfo records of the HEI's current records that carry flags
+func (Transcript *SmartContract) Get_HEI_FlaggedRecords(ctx contractapi.TransactionContextInterface, hei string) ([]*MetaInfo, error) {
+	flagged, err := getMetaInfos(ctx, flaggedLookup(hei))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(flagged) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return flagged, nil
+}
+
+// checkTakenCoursePoint derives the expected point of a taken course from its grade and the student's catalog entry of the course.
+// It returns the disagreement as a flag, or as an error when the HEI rejects such records. A course without a catalog entry is checked
+// when its catalog entry is stored, by checkStoredTakenCoursePoints.
+func checkTakenCoursePoint(ctx contractapi.TransactionContextInterface, config *HEIConfig, scales gradeLookup, course TakenCourse) ([]string, error) {
+	definition := scales.inForce().grade(course.Grade)
+	if definition == nil {
+		return nil, nil
+	}
+
+	info, err := getStudentCourseInfo(ctx, config.Owner, strconv.Itoa(course.StudentID), course.CourseCode)
+	if err != nil || info == nil {
+		return nil, err
+	}
+
+	disagreement := pointDisagreement(config, definition, course, info)
+	if disagreement == "" {
+		return nil, nil
+	}
+
+	if config.PointCheck == pointCheckReject {
+		return nil, fmt.Errorf("the %s", disagreement)
+	}
+
+	return []string{disagreement}, nil
+}
+
+// checkStoredTakenCoursePoints checks the points of the student's current taken courses of a course against its catalog entry being
+// stored. A disagreement is added to the flags of the taken course's MetaInfo record, or fails the insert when the HEI rejects such records.
+func checkStoredTakenCoursePoints(ctx contractapi.TransactionContextInterface, config *HEIConfig, scales gradeLookup, studentID string, info *CourseInfo) error {
+	records, err := getStudentRecordEntries(ctx, config.Owner, "TakenCourse", studentID)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var course TakenCourse
+		err = decodeRecord(ctx, record, &course)
+		if err != nil {
+			return fmt.Errorf("error during fetch taken course record by hash value: %v", err)
+		}
+
+		// Grades recorded under an earlier scale are checked with the meaning they had
+		definition := scales.grade(course.Grade)
+		if course.CourseCode != info.CourseCode || definition == nil {
+			continue
+		}
+
+		disagreement := pointDisagreement(config, definition, course, info)
+		if disagreement == "" || isOneOf(disagreement, record.Flags) {
+			continue
+		}
+
+		if config.PointCheck == pointCheckReject {
+			return fmt.Errorf("the catalog entry disagrees with the taken course %s: the %s", course.HashValue, disagreement)
+		}
+
+		record.Flags = append(record.Flags, disagreement)
+
+		err = updateMetaInfo(ctx, record.MetaInfo)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pointDisagreement describes how the point of a taken course differs from its grade times the course weight, or is empty when they agree
+func pointDisagreement(config *HEIConfig, definition *GradeDefinition, course TakenCourse, info *CourseInfo) string {
+	weight := info.Credit
+	unit := "credits"
+	if config.Weighting == weightingECTS {
+		weight = info.ECTS
+		unit = "ECTS"
+	}
+
+	coefficient, _ := new(big.Rat).SetString(strconv.FormatFloat(definition.Coefficient, 'f', -1, 64))
+	expected := new(big.Rat).Mul(coefficient, big.NewRat(int64(weight), 1))
+
+	if expected.Cmp(course.Point.rat()) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("point %s of %s differs from the expected %s (%s × %d %s)", course.Point, course.CourseCode, decimalFromRat(expected), course.Grade, weight, unit)
+}
+
+// getStudentCourseInfo returns the student's current CourseInfo record of the course, or nil
+func getStudentCourseInfo(ctx contractapi.TransactionContextInterface, hei string, studentID string, courseCode string) (*CourseInfo, error) {
+	records, err := getStudentRecordEntries(ctx, hei, "CourseInfo", studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *CourseInfo
+
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+
+		var info CourseInfo
+		err = decodeRecord(ctx, record, &info)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch course info record by hash value: %v", err)
+		}
+
+		if info.CourseCode == courseCode {
+			found = &info
+		}
+	}
+
+	return found, nil
+}
@@ -0,0 +1,88 @@
+package chaincodeTranscript
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestPointCheckOnInsert(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		courseFirst bool // The CourseInfo is stored before the TakenCourse
+		point       string
+		wantFlag    bool
+		wantErr     string
+	}{
+		{name: "expected point", mode: pointCheckFlag, courseFirst: true, point: "9"},
+		{name: "unexpected point flagged", mode: pointCheckFlag, courseFirst: true, point: "12", wantFlag: true},
+		{name: "unexpected point rejected", mode: pointCheckReject, courseFirst: true, point: "12", wantErr: "differs from the expected 9"},
+		{name: "expected point, catalog entry later", mode: pointCheckFlag, point: "9.0"},
+		{name: "unexpected point flagged when the catalog entry is stored", mode: pointCheckFlag, point: "12", wantFlag: true},
+		{name: "catalog entry rejected over an unexpected point", mode: pointCheckReject, point: "12", wantErr: "catalog entry disagrees with the taken course"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+				return errorOf(ledger.contract.SetPointCheck(ctx, testHEI, test.mode))
+			})
+
+			inserts := []func(ctx contractapi.TransactionContextInterface) error{
+				func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(ledger.contract.InsertNewRecordTakenCourse(ctx, testHEI, 190908809, "COMP2004", "BB", test.point, 4))
+				},
+				func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(ledger.contract.InsertNewRecordCourseInfo(ctx, testHEI, 190908809, "COMP2004", "Course COMP2004", "C", 6, 3))
+				},
+			}
+			if test.courseFirst {
+				inserts[0], inserts[1] = inserts[1], inserts[0]
+			}
+
+			ledger.submit(inserts[0])
+			err := ledger.trySubmit(inserts[1])
+
+			if checkError(t, err, test.wantErr) {
+				return
+			}
+
+			records, err := getStudentMetaInfos(ledger.ctx(), testHEI, "TakenCourse", "190908809")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != 1 {
+				t.Fatalf("got %d taken courses, want 1", len(records))
+			}
+			if flagged := len(records[0].Flags) > 0; flagged != test.wantFlag {
+				t.Errorf("got the flags %v, want flagged: %v", records[0].Flags, test.wantFlag)
+			}
+
+			// The HEI's flagged records list the taken course as well
+			flagged, _ := ledger.contract.Get_HEI_FlaggedRecords(ledger.ctx(), testHEI)
+			if (len(flagged) == 1) != test.wantFlag {
+				t.Errorf("got %d flagged records, want flagged: %v", len(flagged), test.wantFlag)
+			}
+		})
+	}
+}
+
+// A second catalog entry of the course does not repeat the flag of the taken course
+func TestPointCheckFlagsOnce(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.addTakenCourse(190908809, "COMP2004", "BB", "12", 4)
+	ledger.addCourseInfo(190908809, "COMP2004", 6, 3)
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.InsertNewRecordCourseInfo(ctx, testHEI, 190908809, "COMP2004", "Renamed COMP2004", "C", 6, 3))
+	})
+
+	records, err := getStudentMetaInfos(ledger.ctx(), testHEI, "TakenCourse", "190908809")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || len(records[0].Flags) != 1 {
+		t.Fatalf("got %+v, want one taken course with one flag", records)
+	}
+}
@@ -0,0 +1,306 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Class and semester: derived from the registered terms and the earned credits instead of trusting the input
+// *
+// ------------------------------------------------------------------------------------------------------
+
+const (
+	termRegistered = "Registered"
+	termOnLeave    = "Leave of Absence"
+)
+
+var termRegistrationStatuses = []string{termRegistered, termOnLeave}
+
+// TermRegistration records that a student registered for an academic term or was on leave of absence during it
+type TermRegistration struct {
+	StudentID int    `json:"student_id"`
+	Term      string `json:"term"`   // Academic term, e.g. 2023-2024 Fall
+	Status    string `json:"status"` // Registered, Leave of Absence
+	HashValue string `json:"hash_value"`
+}
+
+// StudentProgress compares the class and semester stored in a student's current StudentInfo record with the computed ones
+type StudentProgress struct {
+	StudentID        int      `json:"student_id"`
+	RegisteredTerms  int      `json:"registered_terms"`
+	LeaveTerms       int      `json:"leave_terms"`
+	EarnedLoad       int      `json:"earned_load"` // Earned credits or ECTS following the HEI's GPA weighting
+	LoadUnit         string   `json:"load_unit"`   // credit or ects
+	ComputedSemester int      `json:"computed_semester"`
+	ComputedClass    int      `json:"computed_class"`
+	StoredSemester   int      `json:"stored_semester"`
+	StoredClass      int      `json:"stored_class"`
+	Flags            []string `json:"flags"` // Disagreements between the stored and the computed values
+}
+
+// defaultClassThresholds are the earned loads from which a student is in the second, third and fourth class: a year is 60 ECTS or about 30 credits
+var defaultClassThresholds = map[string][]int{weightingECTS: {60, 120, 180}, weightingCredit: {30, 60, 90}}
+
+func (Transcript *SmartContract) RegisterStudentTerm(ctx contractapi.TransactionContextInterface, owner string, studentId int, term string, status string) (bool, error) {
+	registration := TermRegistration{StudentID: studentId, Term: term, Status: status}
+
+	if term == "" {
+		return false, fmt.Errorf("the term of the registration must not be empty")
+	}
+
+	if !isOneOf(status, termRegistrationStatuses) {
+		return false, fmt.Errorf("unknown term registration status %q, expected one of %v", status, termRegistrationStatuses)
+	}
+
+	registrations, err := getStudentTermRegistrations(ctx, owner, strconv.Itoa(studentId))
+	if err != nil {
+		return false, err
+	}
+
+	for _, existing := range registrations {
+		if existing.Term == term {
+			return false, fmt.Errorf("the student %d is already registered for the term %s as %s", studentId, term, existing.Status)
+		}
+	}
+
+	registration.HashValue = StructToMD5(registration)
+
+	err = putRecordWithMeta(ctx, owner, strconv.Itoa(studentId), "TermRegistration", registration.HashValue, registration)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (Transcript *SmartContract) Get_Student_TermRegistrations(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*TermRegistration, error) {
+	registrations, err := getStudentTermRegistrations(ctx, hei, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(registrations) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return registrations, nil
+}
+
+// SetClassThresholds sets the earned loads, in the unit of the HEI's GPA weighting, from which a student is in the second, third, ... class
+func (Transcript *SmartContract) SetClassThresholds(ctx contractapi.TransactionContextInterface, owner string, thresholds []int) (bool, error) {
+	if len(thresholds) == 0 {
+		return false, fmt.Errorf("the class thresholds must not be empty")
+	}
+
+	for index, threshold := range thresholds {
+		if threshold <= 0 || (index > 0 && threshold <= thresholds[index-1]) {
+			return false, fmt.Errorf("the class thresholds must be positive and increasing: %v", thresholds)
+		}
+	}
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	config.ClassThresholds = thresholds
+
+	return true, putHEIConfig(ctx, config)
+}
+
+// GetStudentProgress computes a student's class and semester and compares them with the current StudentInfo record
+func (Transcript *SmartContract) GetStudentProgress(ctx contractapi.TransactionContextInterface, hei string, studentID string) (*StudentProgress, error) {
+	student, err := Transcript.Get_Student_StudentInfo(ctx, hei, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeStudentProgress(ctx, hei, student)
+}
+
+// CheckStudentProgress computes a student's class and semester again, e.g. after new term registrations, and flags the current
+// StudentInfo record with the disagreements
+func (Transcript *SmartContract) CheckStudentProgress(ctx contractapi.TransactionContextInterface, owner string, studentID string) (*StudentProgress, error) {
+	records, err := getStudentMetaInfos(ctx, owner, "StudentInfo", studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := currentMetaInfo(records)
+	if current == nil {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	var student StudentInfo
+	err = getRecordByHashValue(ctx, current.HashValue, &student)
+	if err != nil {
+		return nil, fmt.Errorf("error during fetch student info record by hash value: %v", err)
+	}
+
+	progress, err := computeStudentProgress(ctx, owner, &student)
+	if err != nil {
+		return nil, err
+	}
+
+	current.Flags = progress.Flags
+
+	return progress, updateMetaInfo(ctx, *current)
+}
+
+// computeStudentProgress derives the semester from the registered terms, leaves of absence not counted, and the class from the earned
+// load. Without any term registration the semester is not derived and stays 0.
+func computeStudentProgress(ctx contractapi.TransactionContextInterface, owner string, student *StudentInfo) (*StudentProgress, error) {
+	progress := StudentProgress{StudentID: student.StudentID}
+	studentID := strconv.Itoa(student.StudentID)
+
+	registrations, err := getStudentTermRegistrations(ctx, owner, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, registration := range registrations {
+		if registration.Status == termOnLeave {
+			progress.LeaveTerms++
+		} else {
+			progress.RegisteredTerms++
+		}
+	}
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.LoadUnit = config.Weighting
+	progress.EarnedLoad, err = studentEarnedLoad(ctx, owner, studentID, config)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.ComputedSemester = progress.RegisteredTerms
+	progress.ComputedClass = 1
+	for _, threshold := range config.classThresholds() {
+		if progress.EarnedLoad >= threshold {
+			progress.ComputedClass++
+		}
+	}
+
+	progress.compareStored(student.Class, student.StudentSemester)
+
+	return &progress, nil
+}
+
+// deriveStudentProgress leaves a class or semester of 0 to the chaincode to derive and flags a given one that disagrees with the derived one
+func deriveStudentProgress(ctx contractapi.TransactionContextInterface, owner string, student *StudentInfo) (*StudentProgress, error) {
+	progress, err := computeStudentProgress(ctx, owner, student)
+	if err != nil {
+		return nil, err
+	}
+
+	if student.Class == 0 {
+		student.Class = progress.ComputedClass
+	}
+
+	if student.StudentSemester == 0 && progress.ComputedSemester > 0 {
+		student.StudentSemester = progress.ComputedSemester
+	}
+
+	progress.compareStored(student.Class, student.StudentSemester)
+
+	return progress, nil
+}
+
+// compareStored flags the stored class and semester when they disagree with the computed ones
+func (progress *StudentProgress) compareStored(class int, semester int) {
+	progress.StoredClass = class
+	progress.StoredSemester = semester
+	progress.Flags = []string{}
+
+	if progress.ComputedSemester > 0 && progress.StoredSemester != progress.ComputedSemester {
+		progress.Flags = append(progress.Flags, fmt.Sprintf("semester %d differs from the computed %d (%d registered terms, %d on leave)",
+			progress.StoredSemester, progress.ComputedSemester, progress.RegisteredTerms, progress.LeaveTerms))
+	}
+
+	if progress.StoredClass != progress.ComputedClass {
+		progress.Flags = append(progress.Flags, fmt.Sprintf("class %d differs from the computed %d (%d %s earned)",
+			progress.StoredClass, progress.ComputedClass, progress.EarnedLoad, progress.LoadUnit))
+	}
+}
+
+// studentEarnedLoad sums the credits or ECTS of the student's passed courses, counting a repeated course once
+func studentEarnedLoad(ctx contractapi.TransactionContextInterface, owner string, studentID string, config *HEIConfig) (int, error) {
+	scales, err := loadGradingScales(ctx, config)
+	if err != nil {
+		return 0, err
+	}
+
+	infos := make(map[string]CourseInfo)
+	var courses []CombinedCourseRecords
+
+	for _, relation := range []string{"CourseInfo", "TakenCourse"} {
+		records, err := getStudentRecordEntries(ctx, owner, relation, studentID)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, record := range records {
+			if record.SupersededBy != "" {
+				continue
+			}
+
+			if relation == "CourseInfo" {
+				var info CourseInfo
+				err = decodeRecord(ctx, record, &info)
+				if err != nil {
+					return 0, fmt.Errorf("error during fetch course info record by hash value: %v", err)
+				}
+				infos[info.CourseCode] = info
+				continue
+			}
+
+			var course TakenCourse
+			err = decodeRecord(ctx, record, &course)
+			if err != nil {
+				return 0, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
+			}
+
+			info, ok := infos[course.CourseCode]
+			if ok {
+				courses = append(courses, CombinedCourseRecords{CourseCode: course.CourseCode, ECTS: info.ECTS, Credit: info.Credit,
+					Grade: course.Grade, Point: course.Point, TakenSemester: course.TakenSemester})
+			}
+		}
+	}
+
+	_, totals := buildTermBlocks(courses, config, scales)
+
+	if config.Weighting == weightingECTS {
+		return totals.EarnedECTS, nil
+	}
+	return totals.EarnedCredits, nil
+}
+
+func getStudentTermRegistrations(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*TermRegistration, error) {
+	var registrations []*TermRegistration
+
+	records, err := getStudentRecordEntries(ctx, hei, "TermRegistration", studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		var registration TermRegistration
+		err = decodeRecord(ctx, record, &registration)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch term registration record by hash value: %v", err)
+		}
+
+		registrations = append(registrations, &registration)
+	}
+
+	return registrations, nil
+}
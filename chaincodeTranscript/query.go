@@ -0,0 +1,397 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * CouchDB (Mango) queries, built as values and marshalled with encoding/json
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// couchQuery is a CouchDB query. It is marshalled with encoding/json, so a value given by a caller, e.g. an HEI name with a quote
+// in it, stays a value and cannot change the query.
+type couchQuery struct {
+	Selector selector            `json:"selector"`
+	Sort     []map[string]string `json:"sort,omitempty"`
+	UseIndex []string            `json:"use_index,omitempty"` // Design document and name of the index the query runs on
+}
+
+// selector matches the documents whose fields equal the given values, or satisfy the given operators, e.g.
+// selector{"owner": hei, "relation": "TakenCourse", "superseded_by": exists(false)}
+type selector map[string]interface{}
+
+// couchIndex is one of the indexes shipped with the chaincode in META-INF/statedb/couchdb/indexes, which Fabric creates on the
+// state database when the chaincode is installed. The list below must be kept in line with those files.
+type couchIndex struct {
+	designDoc string
+	name      string
+	fields    []string
+}
+
+var couchIndexes = []couchIndex{
+	{designDoc: "indexOwnerRelationDoc", name: "indexOwnerRelation", fields: []string{"owner", "relation"}},
+	{designDoc: "indexOwnerRelationStudentDoc", name: "indexOwnerRelationStudent", fields: []string{"owner", "relation", "student_id"}},
+	{designDoc: "indexOwnerStudentHashDoc", name: "indexOwnerStudentHash", fields: []string{"owner", "student_id", "hash_value"}},
+	{designDoc: "indexOwnerFlagsDoc", name: "indexOwnerFlags", fields: []string{"owner", "flags"}},
+}
+
+// newQuery builds a query pinned, through use_index, to the shipped index covering the most fields of the selector
+func newQuery(match selector) *couchQuery {
+	query := couchQuery{Selector: match}
+
+	if index := indexFor(match); index != nil {
+		query.UseIndex = []string{"_design/" + index.designDoc, index.name}
+	}
+
+	return &query
+}
+
+// indexFor returns the shipped index with the most fields among those whose fields are all matched by the selector, or nil
+func indexFor(match selector) *couchIndex {
+	var best *couchIndex
+
+	for index := range couchIndexes {
+		covered := true
+		for _, field := range couchIndexes[index].fields {
+			if _, ok := match[field]; !ok {
+				covered = false
+				break
+			}
+		}
+
+		if covered && (best == nil || len(couchIndexes[index].fields) > len(best.fields)) {
+			best = &couchIndexes[index]
+		}
+	}
+
+	return best
+}
+
+// sortBy sorts the results by a field, ascending unless descending is set; CouchDB needs an index on the sorted fields
+func (query *couchQuery) sortBy(field string, descending bool) *couchQuery {
+	direction := "asc"
+	if descending {
+		direction = "desc"
+	}
+
+	query.Sort = append(query.Sort, map[string]string{field: direction})
+	return query
+}
+
+func (query *couchQuery) toJSON() (string, error) {
+	jsonQuery, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("failed to build the query: %v", err)
+	}
+
+	return string(jsonQuery), nil
+}
+
+// and matches the documents matched by all of the selectors
+func and(selectors ...selector) selector {
+	return selector{"$and": selectors}
+}
+
+// or matches the documents matched by any of the selectors
+func or(selectors ...selector) selector {
+	return selector{"$or": selectors}
+}
+
+// in matches a field equal to one of the values
+func in(values ...interface{}) selector {
+	return selector{"$in": values}
+}
+
+// gt matches a field greater than the value
+func gt(value interface{}) selector {
+	return selector{"$gt": value}
+}
+
+// gte matches a field greater than or equal to the value
+func gte(value interface{}) selector {
+	return selector{"$gte": value}
+}
+
+// lt matches a field less than the value
+func lt(value interface{}) selector {
+	return selector{"$lt": value}
+}
+
+// lte matches a field less than or equal to the value
+func lte(value interface{}) selector {
+	return selector{"$lte": value}
+}
+
+// exists matches a field that is present, or absent when present is false
+func exists(present bool) selector {
+	return selector{"$exists": present}
+}
+
+// regex matches a string field against a regular expression
+func regex(expression string) selector {
+	return selector{"$regex": expression}
+}
+
+// getQueryResult runs the query against the world state
+func getQueryResult(ctx contractapi.TransactionContextInterface, query *couchQuery) (shim.StateQueryIteratorInterface, error) {
+	queryString, err := query.toJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return ctx.GetStub().GetQueryResult(queryString)
+}
+
+// getQueryResultWithPagination runs the query against the world state for one page
+func getQueryResultWithPagination(ctx contractapi.TransactionContextInterface, query *couchQuery, pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, string, int32, error) {
+	queryString, err := query.toJSON()
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	iterator, metadata, err := ctx.GetStub().GetQueryResultWithPagination(queryString, pageSize, bookmark)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	return iterator, metadata.GetBookmark(), metadata.GetFetchedRecordsCount(), nil
+}
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Lookups of MetaInfo records: a Mango query on CouchDB, a composite key range scan on LevelDB
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// A lookup names the MetaInfo records it reads twice: by a selector, which runs as a Mango query on a shipped index when the state
+// database is CouchDB, and by the composite key range that holds the same records, which is scanned when it is LevelDB, as LevelDB has
+// no rich queries. Both return the same records in key order. The peers endorsing a transaction should run the same state database,
+// since Fabric does not re-execute a rich query when it validates a transaction, so a record inserted concurrently into the range a
+// CouchDB lookup read is not detected as a range scan's is.
+
+// lookup reads the entries under one composite key type whose key starts with the given attributes
+type lookup struct {
+	objectType string                      // Composite key type of the entries, "heiID" or a relation key type such as "TakenCourseMeta"
+	attributes []string                    // Owner, then student ID
+	match      selector                    // Selector of the same records, nil to scan the range on CouchDB as well
+	keep       func(record *MetaInfo) bool // Records kept of the range, which the selector matches as well; nil for all
+}
+
+// relationLookup reads the MetaInfo records of a relation of the HEI, or of one student when a student ID is given
+func relationLookup(relation string, hei string, studentID ...string) lookup {
+	match := selector{"owner": hei, "relation": relation}
+	for _, id := range studentID {
+		match["student_id"] = id
+	}
+
+	return lookup{objectType: relationKeyType(relation), attributes: append([]string{hei}, studentID...), match: match}
+}
+
+// current restricts a lookup to the records that are not superseded
+func (search lookup) current() lookup {
+	match := selector{"superseded_by": exists(false)}
+	for field, value := range search.match {
+		match[field] = value
+	}
+
+	search.match = match
+	search.keep = isCurrent
+	return search
+}
+
+// recordLookup reads the MetaInfo record of a student's record with the given hash value, whatever its relation
+func recordLookup(hei string, studentID string, hashValue string) lookup {
+	return lookup{objectType: "heiID", attributes: []string{hei, studentID, hashValue},
+		match: selector{"owner": hei, "student_id": studentID, "hash_value": hashValue}}
+}
+
+// flaggedLookup reads the HEI's current MetaInfo records that carry flags, whatever their relation
+func flaggedLookup(hei string) lookup {
+	return lookup{objectType: "heiID", attributes: []string{hei}, match: selector{"owner": hei, "flags": exists(true), "superseded_by": exists(false)},
+		keep: isFlaggedCurrent}
+}
+
+// richQueryChannels records, by channel, whether the state database runs rich queries
+var richQueryChannels sync.Map
+
+// runsRichQueries tells whether the state database of the channel runs rich queries, which is probed once per channel with a query on a
+// shipped index that matches no record. A probe that fails for another reason leaves the lookups to the range scans, which read the
+// same records on either state database.
+func runsRichQueries(ctx contractapi.TransactionContextInterface) bool {
+	channel := ctx.GetStub().GetChannelID()
+	if supported, ok := richQueryChannels.Load(channel); ok {
+		return supported.(bool)
+	}
+
+	iterator, err := getQueryResult(ctx, newQuery(selector{"owner": "", "relation": ""}))
+	if err == nil {
+		iterator.Close()
+	}
+
+	richQueryChannels.Store(channel, err == nil)
+	return err == nil
+}
+
+// selector returns the selector of the lookup restricted, by the document ID, to its composite key range, since the fields of a
+// MetaInfo record match it under both its "heiID" key and its relation key. CouchDB collates strings in a locale order that does not
+// keep the composite key separators, so the range is matched by a regular expression on the key prefix rather than by a $gte/$lt pair.
+func (search lookup) selector(ctx contractapi.TransactionContextInterface) (selector, error) {
+	prefix, err := ctx.GetStub().CreateCompositeKey(search.objectType, search.attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	match := selector{"_id": regex("^" + regexp.QuoteMeta(prefix))}
+	for field, value := range search.match {
+		match[field] = value
+	}
+
+	return match, nil
+}
+
+// inLookup tells whether a row returned by the Mango query of a lookup is one of its entries, which the selector has already
+// restricted to the lookup's key range
+func (search lookup) inLookup(value []byte) (bool, error) {
+	if search.keep == nil {
+		return true, nil
+	}
+
+	var record MetaInfo
+	err := json.Unmarshal(value, &record)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch json data to struct : %v", err)
+	}
+
+	return search.keep(&record), nil
+}
+
+// iterateLookup calls handle with the value of every entry of the lookup, in key order
+func iterateLookup(ctx contractapi.TransactionContextInterface, search lookup, handle func(value []byte) error) error {
+	if search.match == nil || !runsRichQueries(ctx) {
+		return iterateRange(ctx, search, handle)
+	}
+
+	match, err := search.selector(ctx)
+	if err != nil {
+		return err
+	}
+
+	iterator, err := getQueryResult(ctx, newQuery(match))
+	if err != nil {
+		return fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	defer iterator.Close()
+
+	var rows []*queryresult.KV
+	for iterator.HasNext() {
+		queryRow, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+
+		inLookup, err := search.inLookup(queryRow.Value)
+		if err != nil {
+			return err
+		}
+
+		if inLookup {
+			rows = append(rows, queryRow)
+		}
+	}
+
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+
+	for _, row := range rows {
+		err = handle(row.Value)
+		if err != nil {
+			return fmt.Errorf("failed to fetch json data to struct : %v", err)
+		}
+	}
+
+	return nil
+}
+
+// iterateRange calls handle with the value of every entry of the lookup's composite key range that it keeps
+func iterateRange(ctx contractapi.TransactionContextInterface, search lookup, handle func(value []byte) error) error {
+	return getByPartialCompositeKey(ctx, search.objectType, search.attributes, func(value []byte) error {
+		if search.keep != nil {
+			var record MetaInfo
+			err := json.Unmarshal(value, &record)
+			if err != nil || !search.keep(&record) {
+				return err
+			}
+		}
+
+		return handle(value)
+	})
+}
+
+// iterateLookupPage calls handle with the value of every entry of the lookup among one underlying page of the Mango query or of the
+// composite key range; it returns the bookmark of the next page and the number of rows fetched for the page, which is below the page
+// size for the last page
+func iterateLookupPage(ctx contractapi.TransactionContextInterface, search lookup, pageSize int32, bookmark string, handle func(value []byte) error) (string, int32, error) {
+	if search.match == nil || !runsRichQueries(ctx) {
+		return iterateRangePage(ctx, search, pageSize, bookmark, handle)
+	}
+
+	match, err := search.selector(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+
+	iterator, nextBookmark, fetchedCount, err := getQueryResultWithPagination(ctx, newQuery(match), pageSize, bookmark)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		queryRow, err := iterator.Next()
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to iterate over the returned records : %v", err)
+		}
+
+		inLookup, err := search.inLookup(queryRow.Value)
+		if err != nil {
+			return "", 0, err
+		}
+
+		if !inLookup {
+			continue
+		}
+
+		err = handle(queryRow.Value)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to fetch json data to struct : %v", err)
+		}
+	}
+
+	return nextBookmark, fetchedCount, nil
+}
+
+// iterateRangePage is iterateRange for one page of the composite key range
+func iterateRangePage(ctx contractapi.TransactionContextInterface, search lookup, pageSize int32, bookmark string, handle func(value []byte) error) (string, int32, error) {
+	return getByPartialCompositeKeyPage(ctx, search.objectType, search.attributes, pageSize, bookmark, func(value []byte) error {
+		if search.keep != nil {
+			var record MetaInfo
+			err := json.Unmarshal(value, &record)
+			if err != nil || !search.keep(&record) {
+				return err
+			}
+		}
+
+		return handle(value)
+	})
+}
@@ -0,0 +1,501 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// queryLedger holds records of every kind the queries read, written on a state database that runs rich queries (CouchDB) or not (LevelDB).
+// The student 190908809 has a superseded StudentInfo, a flagged TakenCourse, a course taken in an offering, a minor and a degree award; the
+// student 190908811 has a TakenCourse stored by an earlier version of the chaincode.
+func queryLedger(t *testing.T, couchDB bool) *testLedger {
+	ledger := newTestLedger(t)
+	ledger.stub.couchDB = couchDB
+	contract := ledger.contract
+
+	ledger.addFaculty()
+	ledger.addDepartment("CENG", "Department of Computer Engineering", "COMP")
+
+	transactions := []func(ctx contractapi.TransactionContextInterface) error{
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.RegisterGradingScale(ctx, testHEI, "PERCENT", "Percent scores", "2030-01-01", true, percentGrades))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.SetStandingRules(ctx, testHEI, []StandingRule{{AfterSemester: 2, MinCGPA: 3.5}}))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.DefineLearningOutcome(ctx, testHEI, "CENG-BSc", "PO1", "Apply knowledge of mathematics"))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.MapCourseToOutcome(ctx, testHEI, "CENG-BSc", "PO1", "COMP2004", 5))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.InsertNewRecordCourseOffering(ctx, testHEI, "COMP2004", "2023-2024 Fall", "01", "T-1029", "Dr. Ayse Demir",
+				"English", "Face-to-face"))
+		},
+	}
+	for _, transaction := range transactions {
+		ledger.submit(transaction)
+	}
+
+	ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+	ledger.addStudent(190908810, "Demir", "Department of Computer Engineering", "2021-09-10")
+	ledger.addStudent(190908811, "Kaya", "Department of Computer Engineering", "2023-09-15")
+
+	for _, studentID := range []int{190908809, 190908810, 190908811} {
+		ledger.addCourseInfo(studentID, "COMP2004", 6, 3)
+		ledger.addCourse(studentID, "COMP1001", "AA", "12", 1)
+	}
+	ledger.addTakenCourse(190908810, "COMP2004", "CC", "6", 3)
+	ledger.addTakenCourse(190908809, "COMP1001", "BA", "99", 2) // Flagged: the point is 10.5
+
+	legacy := TakenCourse{StudentID: 190908811, CourseCode: "COMP2004", Grade: "BB", TakenSemester: 2}
+	legacy.Point, _ = parseDecimal("9")
+	legacy.HashValue = StructToMD5(legacy)
+	ledger.putLegacyRecord(190908811, "TakenCourse", legacy.HashValue, legacy)
+
+	if err := ledger.updateStudentInfo(190908809, "Department of Computer Engineering", "Osman", 0, "Corrected the name"); err != nil {
+		t.Fatal(err)
+	}
+
+	transactions = []func(ctx contractapi.TransactionContextInterface) error{
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.InsertNewRecordTakenCourseInOffering(ctx, testHEI, 190908809, "COMP2004", "BB", "9", 3, "2023-2024 Fall", "01"))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.RegisterStudentTerm(ctx, testHEI, 190908809, "2023-2024 Fall", "Registered"))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.InsertNewRecordProgramEnrollment(ctx, testHEI, 190908809, "IE-MINOR", "Faculty of Engineering and Architecture",
+				"Department of Industrial Engineering", "Undergraduate", "Minor", "Minor / Internal", "2023-09-15", "Active"))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			courses, err := getStudentMetaInfos(ctx, testHEI, "TakenCourse", "190908809")
+			if err != nil {
+				return err
+			}
+			return errorOf(contract.AttributeTakenCourseToProgram(ctx, testHEI, 190908809, courses[0].HashValue, "IE-MINOR"))
+		},
+		func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(contract.AwardDegree(ctx, testHEI, 190908809, "", "Bachelor of Science", "2026-06-30", 3, "", "FBU-1"))
+		},
+	}
+	for _, transaction := range transactions {
+		ledger.submit(transaction)
+	}
+
+	return ledger
+}
+
+// firstHashValue returns the hash value of the first current record of a relation of the student 190908809
+func firstHashValue(t *testing.T, ledger *testLedger, relation string) string {
+	t.Helper()
+
+	records, err := getStudentMetaInfos(ledger.ctx(), testHEI, relation, "190908809")
+	if err != nil || len(records) == 0 {
+		t.Fatalf("no %s record: %v", relation, err)
+	}
+
+	return records[0].HashValue
+}
+
+// isQueryTransaction tells whether an exported method of the contract is a transaction that only reads the world state
+func isQueryTransaction(method reflect.Method) bool {
+	contextType := reflect.TypeOf((*contractapi.TransactionContextInterface)(nil)).Elem()
+	if method.Type.NumIn() < 2 || method.Type.In(1) != contextType {
+		return false
+	}
+
+	for _, prefix := range []string{"Get", "Search", "Verify", "IsRecordExists"} {
+		if strings.HasPrefix(method.Name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPaginated tells whether a method takes a page size and a bookmark as its last arguments
+func isPaginated(method reflect.Method) bool {
+	count := method.Type.NumIn()
+	return count >= 2 && method.Type.In(count-2).Kind() == reflect.Int32 && method.Type.In(count-1).Kind() == reflect.String
+}
+
+// runQuery calls a query of the contract with the arguments following the transaction context. A paginated query is run page by page
+// until the bookmark is empty, and the records of all the pages are returned; every page but the last must be full.
+func runQuery(t *testing.T, ledger *testLedger, method reflect.Method, args []interface{}) (interface{}, string) {
+	call := func(args []interface{}) (reflect.Value, string) {
+		in := []reflect.Value{reflect.ValueOf(ledger.contract), reflect.ValueOf(ledger.ctx())}
+		for _, arg := range args {
+			in = append(in, reflect.ValueOf(arg))
+		}
+
+		out := method.Func.Call(in)
+		if err, _ := out[len(out)-1].Interface().(error); err != nil {
+			return reflect.Value{}, err.Error()
+		}
+		return out[0], ""
+	}
+
+	if !isPaginated(method) {
+		result, err := call(args)
+		if err != "" {
+			return nil, err
+		}
+		return result.Interface(), ""
+	}
+
+	const pageSize = 2
+	var records []interface{}
+	bookmark := ""
+
+	for pages := 0; ; pages++ {
+		if pages > 50 {
+			t.Fatalf("%s: the bookmark never gets empty", method.Name)
+		}
+
+		result, err := call(append(args, int32(pageSize), bookmark))
+		if err != "" {
+			return nil, err
+		}
+
+		page := result.Elem()
+		pageRecords := page.FieldByName("Records")
+		for i := 0; i < pageRecords.Len(); i++ {
+			records = append(records, pageRecords.Index(i).Interface())
+		}
+
+		if count := page.FieldByName("FetchedCount").Int(); count != int64(pageRecords.Len()) {
+			t.Errorf("%s: the fetched count is %d for %d records", method.Name, count, pageRecords.Len())
+		}
+
+		bookmark = page.FieldByName("Bookmark").String()
+		if bookmark == "" {
+			break
+		}
+		if pageRecords.Len() != pageSize {
+			t.Errorf("%s: got a page of %d records before the last page, want %d", method.Name, pageRecords.Len(), pageSize)
+		}
+	}
+
+	return records, ""
+}
+
+// queryArgs returns the arguments, following the transaction context, each query of the contract is run with on a queryLedger. The page
+// size and the bookmark of a paginated query are left out.
+func queryArgs(t *testing.T, ledger *testLedger) map[string][]interface{} {
+	const student = "190908809"
+	asOf := ledger.stub.now.Add(-4 * ledger.stub.step).Format("2006-01-02T15:04:05Z07:00")
+	studentInfoHash := firstHashValue(t, ledger, "StudentInfo")
+	courseInfoHash := firstHashValue(t, ledger, "CourseInfo")
+	takenCourseHash := firstHashValue(t, ledger, "TakenCourse")
+
+	return map[string][]interface{}{
+		"IsRecordExists":                                {testHEI, student, takenCourseHash},
+		"GetStudentTranscript":                          {testHEI, student},
+		"GetStudentTranscriptStrict":                    {testHEI, student},
+		"GetStudentTranscriptAsOf":                      {testHEI, student, asOf},
+		"GetStudentTranscriptDiff":                      {testHEI, student, asOf, ""},
+		"GetStudentProgramTranscript":                   {testHEI, student, "IE-MINOR"},
+		"GetStudentCompetencyProfile":                   {testHEI, student, "CENG-BSc"},
+		"GetStudentCohortRank":                          {testHEI, student},
+		"GetStudentProgress":                            {testHEI, student},
+		"GetHonorStudents":                              {testHEI, "CENG", "2023-2024 Fall"},
+		"GetProbationStudents":                          {testHEI, "CENG"},
+		"GetCourseStatistics":                           {testHEI, "COMP2004", "2023-2024 Fall"},
+		"VerifyDegreeAward":                             {testHEI, "FBU-1"},
+		"SearchStudents":                                {testHEI, `{"department":"CENG"}`, "registration_date", false},
+		"SearchTakenCourses":                            {testHEI, `{"course_code":"COMP1001"}`, "grade", true},
+		"Get_HEI_Config":                                {testHEI},
+		"Get_HEI_GradingScales":                         {testHEI},
+		"Get_HEI_GradingScaleInForce":                   {testHEI},
+		"Get_HEI_Faculties":                             {testHEI},
+		"Get_HEI_Departments":                           {testHEI},
+		"Get_HEI_CourseOfferings":                       {testHEI},
+		"Get_HEI_FlaggedRecords":                        {testHEI},
+		"Get_HEI_TakenCourses":                          {testHEI},
+		"Get_HEI_CourseInfos":                           {testHEI},
+		"Get_HEI_StudentInfos":                          {testHEI},
+		"Get_HEI_MetaInfos_TakenCourses":                {testHEI},
+		"Get_HEI_MetaInfos_CourseInfos":                 {testHEI},
+		"Get_HEI_MetaInfos_StudentInfos":                {testHEI},
+		"Get_HEI_TakenCourses_Paginated":                {testHEI},
+		"Get_HEI_CourseInfos_Paginated":                 {testHEI},
+		"Get_HEI_StudentInfos_Paginated":                {testHEI},
+		"Get_HEI_MetaInfos_TakenCourses_Paginated":      {testHEI},
+		"Get_HEI_MetaInfos_CourseInfos_Paginated":       {testHEI},
+		"Get_HEI_MetaInfos_StudentInfos_Paginated":      {testHEI},
+		"Get_HEI_FlaggedRecords_Paginated":              {testHEI},
+		"Get_HEI_CourseOfferings_Paginated":             {testHEI},
+		"Get_HEI_Faculties_Paginated":                   {testHEI},
+		"Get_HEI_Departments_Paginated":                 {testHEI},
+		"Get_HEI_GradingScales_Paginated":               {testHEI},
+		"Get_CourseOffering":                            {testHEI, "COMP2004", "2023-2024 Fall", "01"},
+		"Get_Program_LearningOutcomes":                  {testHEI, "CENG-BSc"},
+		"Get_Program_OutcomeMappings":                   {testHEI, "CENG-BSc"},
+		"Get_Student_StudentInfo":                       {testHEI, student},
+		"Get_Student_CourseInfos":                       {testHEI, student},
+		"Get_Student_TakenCourses":                      {testHEI, student},
+		"Get_Student_StudentInfo_HashValues":            {testHEI, student},
+		"Get_Student_CourseInfos_HashValues":            {testHEI, student},
+		"Get_Student_TakenCourses_HashValues":           {testHEI, student},
+		"Get_Student_StudentInfo_Versions":              {testHEI, student},
+		"Get_Student_ProgramEnrollments":                {testHEI, student},
+		"Get_Student_DegreeAwards":                      {testHEI, student},
+		"Get_Student_TermRegistrations":                 {testHEI, student},
+		"Get_Student_Standing":                          {testHEI, student},
+		"Get_Student_TakenCourses_Paginated":            {testHEI, student},
+		"Get_Student_CourseInfos_Paginated":             {testHEI, student},
+		"Get_Student_StudentInfo_HashValues_Paginated":  {testHEI, student},
+		"Get_Student_CourseInfos_HashValues_Paginated":  {testHEI, student},
+		"Get_Student_TakenCourses_HashValues_Paginated": {testHEI, student},
+		"Get_Student_StudentInfo_Versions_Paginated":    {testHEI, student},
+		"Get_Student_ProgramEnrollments_Paginated":      {testHEI, student},
+		"Get_Student_DegreeAwards_Paginated":            {testHEI, student},
+		"Get_Student_TermRegistrations_Paginated":       {testHEI, student},
+		"Get_StudentInfo_ByHashValue":                   {studentInfoHash},
+		"Get_CourseInfo_ByHashValue":                    {courseInfoHash},
+		"Get_TakenCourse_ByHashValue":                   {takenCourseHash},
+	}
+}
+
+// Every query returns the same result whether the state database runs the rich queries (CouchDB) or the range scans serve them (LevelDB)
+func TestQueriesAgreeOnCouchDBAndLevelDB(t *testing.T) {
+	levelDB := queryLedger(t, false)
+	couchDB := queryLedger(t, true)
+
+	if !reflect.DeepEqual(levelDB.stub.state, couchDB.stub.state) {
+		t.Fatal("the transactions wrote different world states")
+	}
+	if len(couchDB.stub.queries) == 0 {
+		t.Fatal("no rich query was run on CouchDB")
+	}
+
+	queries := queryArgs(t, levelDB)
+
+	contractType := reflect.TypeOf(levelDB.contract)
+	for i := 0; i < contractType.NumMethod(); i++ {
+		method := contractType.Method(i)
+		if !isQueryTransaction(method) {
+			continue
+		}
+
+		args, ok := queries[method.Name]
+		if !ok {
+			t.Errorf("the query %s is not run on both state databases", method.Name)
+			continue
+		}
+
+		t.Run(method.Name, func(t *testing.T) {
+			levelResult, levelErr := runQuery(t, levelDB, method, args)
+			couchResult, couchErr := runQuery(t, couchDB, method, args)
+
+			if levelErr != couchErr {
+				t.Fatalf("got the error %q on LevelDB and %q on CouchDB", levelErr, couchErr)
+			}
+
+			levelJSON, _ := json.Marshal(levelResult)
+			couchJSON, _ := json.Marshal(couchResult)
+			if string(levelJSON) != string(couchJSON) {
+				t.Errorf("got\n%s\non LevelDB and\n%s\non CouchDB", levelJSON, couchJSON)
+			}
+		})
+	}
+}
+
+// A page holds pageSize records even when the records the lookup skips (superseded versions)
+// fill the underlying pages, and the pages list every record once
+func TestPagesAreFilledToThePageSize(t *testing.T) {
+	for _, couchDB := range []bool{false, true} {
+		t.Run(fmt.Sprintf("CouchDB %v", couchDB), func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.stub.couchDB = couchDB
+
+			// Each update supersedes the previous StudentInfo version, which stays under the student's keys
+			ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+			for version := 1; version <= 4; version++ {
+				err := ledger.updateStudentInfo(190908809, "Department of Computer Engineering", fmt.Sprint("Name", version), 0, "Corrected the name")
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, studentID := range []int{190908810, 190908811, 190908812} {
+				ledger.addStudent(studentID, "Demir", "Department of Computer Engineering", "2022-09-02")
+			}
+
+			tests := []struct {
+				pageSize int32
+				want     []int // Number of records in each page
+			}{
+				{pageSize: 1, want: []int{1, 1, 1, 1}},
+				{pageSize: 2, want: []int{2, 2}},
+				{pageSize: 3, want: []int{3, 1}},
+				{pageSize: 4, want: []int{4}},
+				{pageSize: 10, want: []int{4}},
+			}
+
+			for _, test := range tests {
+				var sizes []int
+				seen := make(map[string]bool)
+				bookmark := ""
+
+				for len(sizes) <= len(test.want) {
+					page, err := ledger.contract.Get_HEI_StudentInfos_Paginated(ledger.ctx(), testHEI, test.pageSize, bookmark)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					sizes = append(sizes, len(page.Records))
+					for _, record := range page.Records {
+						if seen[record.HashValue] {
+							t.Errorf("page size %d: the student %d is listed twice", test.pageSize, record.StudentID)
+						}
+						seen[record.HashValue] = true
+					}
+
+					bookmark = page.Bookmark
+					if bookmark == "" {
+						break
+					}
+				}
+
+				// An exhausted range may leave a bookmark behind its last full page, whose next page is empty
+				if len(sizes) == len(test.want)+1 && sizes[len(sizes)-1] == 0 {
+					sizes = sizes[:len(sizes)-1]
+				}
+				if !reflect.DeepEqual(sizes, test.want) {
+					t.Errorf("page size %d: got pages of %v records, want %v", test.pageSize, sizes, test.want)
+				}
+			}
+		})
+	}
+}
+
+// Every rich query the contract runs, in the transactions that write records and in the queries, names through use_index a shipped index
+// whose fields its selector matches, so that CouchDB does not scan the whole state database
+func TestRichQueriesRunOnShippedIndexes(t *testing.T) {
+	ledger := queryLedger(t, true)
+
+	contractType := reflect.TypeOf(ledger.contract)
+	for name, args := range queryArgs(t, ledger) {
+		method, ok := contractType.MethodByName(name)
+		if !ok {
+			t.Fatalf("the contract has no query %s", name)
+		}
+		runQuery(t, ledger, method, args)
+	}
+
+	used := make(map[string]bool)
+	for _, queryString := range ledger.stub.queries {
+		var query couchQuery
+		err := json.Unmarshal([]byte(queryString), &query)
+		if err != nil {
+			t.Fatalf("invalid query %s: %v", queryString, err)
+		}
+
+		index := indexFor(query.Selector)
+		if index == nil {
+			t.Errorf("no shipped index serves the query %s", queryString)
+			continue
+		}
+
+		want := []string{"_design/" + index.designDoc, index.name}
+		if !reflect.DeepEqual(query.UseIndex, want) {
+			t.Errorf("the query %s uses the index %v, want %v", queryString, query.UseIndex, want)
+		}
+		used[index.name] = true
+	}
+
+	for _, index := range couchIndexes {
+		if !used[index.name] {
+			t.Errorf("no query runs on the shipped index %s", index.name)
+		}
+	}
+}
+
+// The selector of every rich query matches the entries of one composite key type only, not the same MetaInfo record under its "heiID"
+// key and its relation key
+func TestRichQueriesMatchOneKeyType(t *testing.T) {
+	ledger := queryLedger(t, true)
+
+	contractType := reflect.TypeOf(ledger.contract)
+	for name, args := range queryArgs(t, ledger) {
+		method, _ := contractType.MethodByName(name)
+		runQuery(t, ledger, method, args)
+	}
+
+	for _, queryString := range ledger.stub.queries {
+		keys, err := ledger.stub.runQuery(queryString)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		objectTypes := make(map[string]bool)
+		for _, key := range keys {
+			objectType, _, _ := ledger.stub.SplitCompositeKey(key)
+			objectTypes[objectType] = true
+		}
+
+		if len(objectTypes) > 1 {
+			t.Errorf("the query %s matches the keys of %d types", queryString, len(objectTypes))
+		}
+	}
+}
+
+// The indexes the queries name are the ones shipped in META-INF/statedb/couchdb/indexes
+func TestCouchIndexesAreShipped(t *testing.T) {
+	files, err := filepath.Glob("../META-INF/statedb/couchdb/indexes/*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type indexDefinition struct {
+		Index struct {
+			Fields []string `json:"fields"`
+		} `json:"index"`
+		DesignDoc string `json:"ddoc"`
+		Name      string `json:"name"`
+		Type      string `json:"type"`
+	}
+
+	shipped := make(map[string]indexDefinition)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var definition indexDefinition
+		err = json.Unmarshal(content, &definition)
+		if err != nil {
+			t.Fatalf("invalid index definition %s: %v", file, err)
+		}
+		if definition.Type != "json" || definition.Name+".json" != filepath.Base(file) {
+			t.Errorf("the index definition %s is of type %q and named %q", file, definition.Type, definition.Name)
+		}
+
+		shipped[definition.Name] = definition
+	}
+
+	if len(shipped) != len(couchIndexes) {
+		t.Errorf("got %d shipped indexes, want %d", len(shipped), len(couchIndexes))
+	}
+
+	for _, index := range couchIndexes {
+		definition, ok := shipped[index.name]
+		if !ok {
+			t.Errorf("the index %s is not shipped", index.name)
+			continue
+		}
+		if definition.DesignDoc != index.designDoc || !reflect.DeepEqual(definition.Index.Fields, index.fields) {
+			t.Errorf("the index %s is shipped in the design document %s on the fields %v, want %s on %v", index.name, definition.DesignDoc,
+				definition.Index.Fields, index.designDoc, index.fields)
+		}
+	}
+}
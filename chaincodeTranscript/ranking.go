@@ -0,0 +1,121 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Cohort ranking: a student's class rank by CGPA among the students of the same department and entry year
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// CohortRank is the position of one student in their cohort; it carries no grades of the other students
+type CohortRank struct {
+	StudentID  int     `json:"student_id"`
+	Department string  `json:"department"` // Department code, or the department name while the HEI has not registered codes
+	EntryYear  int     `json:"entry_year"` // Year of the registration date
+	CGPA       float64 `json:"cgpa"`
+	Rank       int     `json:"rank"`                                    // 1 for the highest CGPA; students with equal CGPAs share a rank
+	CohortSize int     `json:"cohort_size"`                             // Students of the cohort with at least one course counted in the CGPA
+	Percentile float64 `json:"percentile"`                              // Share of the cohort, in percent, ranked at or below the student, e.g. 100 for the first
+	Unranked   []int   `json:"unranked,omitempty" metadata:",optional"` // Students of the department whose entry year cannot be read from their registration date
+}
+
+// GetStudentCohortRank computes the rank and percentile of a student by CGPA within their department and entry cohort
+func (Transcript *SmartContract) GetStudentCohortRank(ctx contractapi.TransactionContextInterface, hei string, studentID string) (*CohortRank, error) {
+	transcript, err := Transcript.buildStudentTranscript(ctx, hei, studentID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if transcript.Completeness.MissingStudentInfo {
+		return nil, fmt.Errorf("there is not a student info record of the student %s", studentID)
+	}
+
+	student := transcript.InfoStudent
+
+	if !countsInCGPA(transcript) {
+		return nil, fmt.Errorf("the student %s has not any course counted in the CGPA to be ranked by", studentID)
+	}
+
+	entryYear, err := entryYearOf(&student)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := CohortRank{StudentID: student.StudentID, Department: student.Department, EntryYear: entryYear, CGPA: transcript.CGPA, Rank: 1}
+	if student.DepartmentCode != "" {
+		rank.Department = student.DepartmentCode
+	}
+
+	students, err := Transcript.Get_HEI_StudentInfos(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	counted := make(map[int]bool)
+
+	for _, other := range students {
+		if counted[other.StudentID] || !inSameDepartment(&student, other) {
+			continue
+		}
+
+		counted[other.StudentID] = true
+
+		// A student without a readable registration date may belong to the cohort, so the rank is reported as uncertain
+		otherYear, err := entryYearOf(other)
+		if err != nil {
+			rank.Unranked = append(rank.Unranked, other.StudentID)
+			continue
+		}
+
+		if otherYear != entryYear {
+			continue
+		}
+
+		// A student whose transcript cannot be constructed would be left out of the cohort unnoticed, which changes every rank of it
+		otherTranscript, err := Transcript.buildStudentTranscript(ctx, hei, strconv.Itoa(other.StudentID), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct the transcript of the student %d of the cohort: %v", other.StudentID, err)
+		}
+
+		if !countsInCGPA(otherTranscript) {
+			continue
+		}
+
+		rank.CohortSize++
+		if otherTranscript.CGPA > rank.CGPA {
+			rank.Rank++
+		}
+	}
+
+	rank.Percentile = roundTo2(float64(rank.CohortSize-rank.Rank+1) / float64(rank.CohortSize) * 100)
+
+	return &rank, nil
+}
+
+func countsInCGPA(transcript *StudentTranscript) bool {
+	return transcript.Totals.GPACredits > 0 || transcript.Totals.GPAECTS > 0
+}
+
+// entryYearOf reads the entry cohort of a student from the registration date
+func entryYearOf(student *StudentInfo) (int, error) {
+	date, err := normalizeDate(student.RegistrationDate)
+	if err != nil {
+		return 0, fmt.Errorf("the registration date of the student %d: %v", student.StudentID, err)
+	}
+
+	return strconv.Atoi(date[:4])
+}
+
+// inSameDepartment compares the department codes of two students, or their department names when either has no code
+func inSameDepartment(student *StudentInfo, other *StudentInfo) bool {
+	if student.DepartmentCode != "" && other.DepartmentCode != "" {
+		return student.DepartmentCode == other.DepartmentCode
+	}
+	return normalizeUnitName(student.Department) == normalizeUnitName(other.Department)
+}
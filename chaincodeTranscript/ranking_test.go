@@ -0,0 +1,90 @@
+package chaincodeTranscript
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// cohortLedger holds three students of the same department and entry year, and one of another year
+func cohortLedger(t *testing.T) *testLedger {
+	ledger := newTestLedger(t)
+
+	students := []struct {
+		id    int
+		year  string
+		grade string
+	}{{190908801, "2022-09-02", "AA"}, {190908802, "02.09.2022", "BB"}, {190908803, "2022-09-15", "BB"}, {200908804, "2023-09-01", "CC"}}
+
+	for _, student := range students {
+		ledger.addStudent(student.id, "Student", "Department of Computer Engineering", student.year)
+		ledger.addCourse(student.id, "COMP1001", student.grade, "0", 1)
+	}
+
+	return ledger
+}
+
+func TestGetStudentCohortRank(t *testing.T) {
+	tests := []struct {
+		studentID      string
+		wantRank       int
+		wantCohortSize int
+		wantPercentile float64
+	}{
+		{studentID: "190908801", wantRank: 1, wantCohortSize: 3, wantPercentile: 100},
+		{studentID: "190908802", wantRank: 2, wantCohortSize: 3, wantPercentile: 66.67},
+		{studentID: "190908803", wantRank: 2, wantCohortSize: 3, wantPercentile: 66.67},
+		{studentID: "200908804", wantRank: 1, wantCohortSize: 1, wantPercentile: 100},
+	}
+
+	ledger := cohortLedger(t)
+
+	for _, test := range tests {
+		t.Run(test.studentID, func(t *testing.T) {
+			rank, err := ledger.contract.GetStudentCohortRank(ledger.ctx(), testHEI, test.studentID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rank.Rank != test.wantRank || rank.CohortSize != test.wantCohortSize || rank.Percentile != test.wantPercentile {
+				t.Errorf("got rank %d of %d (%v%%), want %d of %d (%v%%)", rank.Rank, rank.CohortSize, rank.Percentile,
+					test.wantRank, test.wantCohortSize, test.wantPercentile)
+			}
+		})
+	}
+}
+
+// A student of the cohort whose transcript cannot be constructed fails the ranking instead of being left out of the cohort
+func TestGetStudentCohortRankReportsABrokenTranscript(t *testing.T) {
+	ledger := cohortLedger(t)
+
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		meta := MetaInfo{Owner: testHEI, StudentID: "190908802", Relation: "TakenCourse", HashValue: "0123456789abcdef0123456789abcdef"}
+		return putMetaInfo(ctx, meta, nil)
+	})
+
+	_, err := ledger.contract.GetStudentCohortRank(ledger.ctx(), testHEI, "190908801")
+	if err == nil || !strings.Contains(err.Error(), "the student 190908802 of the cohort") {
+		t.Fatalf("got the error %v, want the transcript error of the student 190908802", err)
+	}
+}
+
+// A student of the department whose registration date cannot be read is reported as unranked instead of being left out unnoticed
+func TestGetStudentCohortRankReportsAnUnreadableEntryYear(t *testing.T) {
+	ledger := cohortLedger(t)
+
+	broken := StudentInfo{Department: "Department of Computer Engineering", StudentID: 190908805, StudentSurname: "Broken", RegistrationDate: "2022/09/02"}
+	broken.HashValue = StructToMD5(broken)
+	ledger.putLegacyRecord(broken.StudentID, "StudentInfo", broken.HashValue, broken)
+
+	rank, err := ledger.contract.GetStudentCohortRank(ledger.ctx(), testHEI, "190908801")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rank.CohortSize != 3 || !reflect.DeepEqual(rank.Unranked, []int{190908805}) {
+		t.Errorf("got the cohort size %d and the unranked students %v, want 3 and [190908805]", rank.CohortSize, rank.Unranked)
+	}
+}
@@ -0,0 +1,530 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Search: an HEI's students and taken courses selected by a structured filter, sorted and paginated
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// SearchFilter selects students by their current StudentInfo record and taken courses by their current TakenCourse records; it is given
+// to the searches as JSON, in which a missing field matches any value. E.g. {"department": "CENG", "program_type": "Undergraduate", "registered_from": "2020-01-01", "registered_to": "2021-12-31"}
+// or {"course_code": "STAT2003", "grades": ["DC", "DD", "FD", "FF"]}.
+type SearchFilter struct {
+	Faculty        string   `json:"faculty,omitempty"`         // Registered code or name of the faculty
+	Department     string   `json:"department,omitempty"`      // Registered code or name of the department
+	ProgramType    string   `json:"program_type,omitempty"`    // e.g. Undergraduate
+	RegisteredFrom string   `json:"registered_from,omitempty"` // Earliest registration date, YYYY-MM-DD or DD.MM.YYYY
+	RegisteredTo   string   `json:"registered_to,omitempty"`   // Latest registration date, YYYY-MM-DD or DD.MM.YYYY
+	CourseCode     string   `json:"course_code,omitempty"`     // Code of a taken course
+	Grades         []string `json:"grades,omitempty"`          // Letter grades of a taken course, any of them matches
+	Term           string   `json:"term,omitempty"`            // Academic term of a taken course, e.g. 2023-2024 Fall
+}
+
+// Search results are sorted, and a page is then cut from them after the record the bookmark names. Every page reads and sorts all of the
+// HEI's current StudentInfo records, and TakenCourse records when the filter or the search needs them, so a page costs as much as the
+// whole search. The bookmark holds the sort field, the student ID and the hash value of the last record of the previous page, so a record
+// inserted or superseded between two pages neither repeats nor skips a record on the next page; a record whose sort field changed in
+// between may still move from one side of the bookmark to the other. The bookmark of the last page is empty.
+
+// SearchPage is one page of the records found by a search; like Page, each search returns a named page type defined on it
+type SearchPage[T any] struct {
+	Records                  []T    `json:"records"`
+	Bookmark                 string `json:"bookmark"`
+	FetchedCount             int32  `json:"fetched_count"`
+	TotalCount               int32  `json:"total_count"`                                               // Number of records found on all pages
+	InvalidRegistrationDates []int  `json:"invalid_registration_dates,omitempty" metadata:",optional"` // Students left out because their stored registration date is not a date
+}
+
+// StudentSearchPage is one page of the students found by SearchStudents
+type StudentSearchPage SearchPage[*StudentInfo]
+
+// TakenCourseSearchPage is one page of the taken courses found by SearchTakenCourses
+type TakenCourseSearchPage SearchPage[*TakenCourse]
+
+var studentSortFields = []string{"student_id", "student_surname", "registration_date", "department", "program_type"}
+
+var takenCourseSortFields = []string{"student_id", "course_code", "grade", "point", "taken_semester", "term"}
+
+// SearchStudents lists the HEI's students matched by the filter. When the filter has course fields, a student is listed when one of
+// their taken courses matches them. The students are sorted by one of student_id (the default), student_surname, registration_date,
+// department and program_type.
+func (Transcript *SmartContract) SearchStudents(ctx contractapi.TransactionContextInterface, hei string, filter string, sortBy string, descending bool,
+	pageSize int32, bookmark string) (*StudentSearchPage, error) {
+
+	page := StudentSearchPage{Records: []*StudentInfo{}}
+
+	search, err := newStudentSearch(ctx, hei, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if sortBy == "" {
+		sortBy = "student_id"
+	}
+
+	if !isOneOf(sortBy, studentSortFields) {
+		return nil, fmt.Errorf("unknown sort field %q, expected one of %v", sortBy, studentSortFields)
+	}
+
+	students, err := search.currentStudents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []*StudentInfo
+
+	if search.hasCourseFields() {
+		courses, err := search.currentTakenCourses(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		withCourse := make(map[int]bool)
+		for _, course := range courses {
+			if search.matchesCourse(course) {
+				withCourse[course.StudentID] = true
+			}
+		}
+
+		for _, student := range students {
+			if !withCourse[student.StudentID] {
+				continue
+			}
+
+			if search.matchesStudent(student) {
+				found = append(found, student)
+			}
+		}
+	} else {
+		for _, student := range students {
+			if search.matchesStudent(student) {
+				found = append(found, student)
+			}
+		}
+	}
+
+	before := func(a *StudentInfo, b *StudentInfo) bool {
+		if descending {
+			a, b = b, a
+		}
+		return studentLess(a, b, sortBy)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return before(found[i], found[j]) })
+
+	start, end, next, err := searchPageBounds(found, pageSize, bookmark, before, func(student *StudentInfo) *StudentInfo {
+		return studentSortKey(student, sortBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page.Records = append(page.Records, found[start:end]...)
+	page.Bookmark = next
+	page.FetchedCount = int32(end - start)
+	page.TotalCount = int32(len(found))
+	page.InvalidRegistrationDates = search.invalidDates
+
+	return &page, nil
+}
+
+// SearchTakenCourses lists the HEI's taken courses matched by the course fields of the filter whose student is matched by its student
+// fields; with student fields, courses of a student without a current StudentInfo record are left out. The courses are sorted by one of
+// student_id (the default), course_code, grade, point, taken_semester and term.
+func (Transcript *SmartContract) SearchTakenCourses(ctx contractapi.TransactionContextInterface, hei string, filter string, sortBy string, descending bool,
+	pageSize int32, bookmark string) (*TakenCourseSearchPage, error) {
+
+	page := TakenCourseSearchPage{Records: []*TakenCourse{}}
+
+	search, err := newStudentSearch(ctx, hei, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if sortBy == "" {
+		sortBy = "student_id"
+	}
+
+	if !isOneOf(sortBy, takenCourseSortFields) {
+		return nil, fmt.Errorf("unknown sort field %q, expected one of %v", sortBy, takenCourseSortFields)
+	}
+
+	courses, err := search.currentTakenCourses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matchedStudents map[int]bool
+	if search.hasStudentFields() {
+		students, err := search.currentStudents(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		matchedStudents = make(map[int]bool)
+		for _, student := range students {
+			if search.matchesStudent(student) {
+				matchedStudents[student.StudentID] = true
+			}
+		}
+	}
+
+	var found []*TakenCourse
+	for _, course := range courses {
+		if search.matchesCourse(course) && (matchedStudents == nil || matchedStudents[course.StudentID]) {
+			found = append(found, course)
+		}
+	}
+
+	before := func(a *TakenCourse, b *TakenCourse) bool {
+		if descending {
+			a, b = b, a
+		}
+		return takenCourseLess(a, b, sortBy)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return before(found[i], found[j]) })
+
+	start, end, next, err := searchPageBounds(found, pageSize, bookmark, before, func(course *TakenCourse) *TakenCourse {
+		return takenCourseSortKey(course, sortBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page.Records = append(page.Records, found[start:end]...)
+	page.Bookmark = next
+	page.FetchedCount = int32(end - start)
+	page.TotalCount = int32(len(found))
+	page.InvalidRegistrationDates = search.invalidDates
+
+	return &page, nil
+}
+
+// studentSearch is a validated search filter with the HEI it searches
+type studentSearch struct {
+	hei          string
+	filter       SearchFilter
+	grades       map[string]bool
+	inFaculty    func(code string, name string) bool
+	inDepartment func(code string, name string) bool
+	invalidDates []int // Students a registration date filter left out because their registration date is not a date
+}
+
+func newStudentSearch(ctx contractapi.TransactionContextInterface, hei string, jsonFilter string) (*studentSearch, error) {
+	var filter SearchFilter
+
+	// An empty filter matches every record; unknown fields are rejected so a misspelled field does not widen the search
+	if strings.TrimSpace(jsonFilter) != "" {
+		decoder := json.NewDecoder(strings.NewReader(jsonFilter))
+		decoder.DisallowUnknownFields()
+
+		err := decoder.Decode(&filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search filter: %v", err)
+		}
+	}
+
+	var err error
+
+	search := studentSearch{hei: hei, filter: filter, grades: make(map[string]bool)}
+
+	for _, grade := range filter.Grades {
+		search.grades[grade] = true
+	}
+
+	for _, date := range []*string{&search.filter.RegisteredFrom, &search.filter.RegisteredTo} {
+		if *date == "" {
+			continue
+		}
+		*date, err = normalizeDate(*date)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if search.filter.RegisteredFrom != "" && search.filter.RegisteredTo != "" && search.filter.RegisteredFrom > search.filter.RegisteredTo {
+		return nil, fmt.Errorf("the registration date range is empty: %s is after %s", search.filter.RegisteredFrom, search.filter.RegisteredTo)
+	}
+
+	if filter.Faculty != "" || filter.Department != "" {
+		organization, err := loadOrganization(ctx, hei)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter.Faculty != "" {
+			search.inFaculty = organization.facultyFilter(filter.Faculty)
+		}
+		if filter.Department != "" {
+			search.inDepartment = organization.departmentFilter(filter.Department)
+		}
+	}
+
+	return &search, nil
+}
+
+func (search *studentSearch) hasStudentFields() bool {
+	return search.filter.Faculty != "" || search.filter.Department != "" || search.filter.ProgramType != "" ||
+		search.filter.RegisteredFrom != "" || search.filter.RegisteredTo != ""
+}
+
+func (search *studentSearch) hasCourseFields() bool {
+	return search.filter.CourseCode != "" || len(search.filter.Grades) > 0 || search.filter.Term != ""
+}
+
+// matchesStudent compares the registration date of a student in ISO-8601, as a stored record may still hold a DD.MM.YYYY date. A student
+// whose registration date is not a date is not matched by a registration date filter, and is recorded so the search can report it.
+func (search *studentSearch) matchesStudent(student *StudentInfo) bool {
+	if search.inFaculty != nil && !search.inFaculty(student.FacultyCode, student.Faculty) {
+		return false
+	}
+
+	if search.inDepartment != nil && !search.inDepartment(student.DepartmentCode, student.Department) {
+		return false
+	}
+
+	if search.filter.ProgramType != "" && student.ProgramType != search.filter.ProgramType {
+		return false
+	}
+
+	if search.filter.RegisteredFrom != "" || search.filter.RegisteredTo != "" {
+		registrationDate, err := normalizeDate(student.RegistrationDate)
+		if err != nil {
+			search.invalidDates = append(search.invalidDates, student.StudentID)
+			return false
+		}
+
+		if search.filter.RegisteredFrom != "" && registrationDate < search.filter.RegisteredFrom {
+			return false
+		}
+
+		if search.filter.RegisteredTo != "" && registrationDate > search.filter.RegisteredTo {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (search *studentSearch) matchesCourse(course *TakenCourse) bool {
+	if search.filter.CourseCode != "" && course.CourseCode != search.filter.CourseCode {
+		return false
+	}
+
+	if len(search.grades) > 0 && !search.grades[course.Grade] {
+		return false
+	}
+
+	if search.filter.Term != "" && course.Term != search.filter.Term {
+		return false
+	}
+
+	return true
+}
+
+// currentStudents returns the current StudentInfo record of each of the HEI's students, in the order of their keys
+func (search *studentSearch) currentStudents(ctx contractapi.TransactionContextInterface) ([]*StudentInfo, error) {
+	var students []*StudentInfo
+
+	entries, err := getHEIRecordEntries(ctx, search.hei, "StudentInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	// The entries of a student are adjacent, as the keys start with the student ID
+	for start := 0; start < len(entries); {
+		end := start
+		for end < len(entries) && entries[end].StudentID == entries[start].StudentID {
+			end++
+		}
+
+		current := currentRecordEntry(entries[start:end])
+		if current != nil {
+			var student StudentInfo
+			err = decodeRecord(ctx, current, &student)
+			if err != nil {
+				return nil, fmt.Errorf("error during fetch student info record by hash value: %v", err)
+			}
+			students = append(students, &student)
+		}
+
+		start = end
+	}
+
+	return students, nil
+}
+
+// currentTakenCourses returns the HEI's unsuperseded TakenCourse records
+func (search *studentSearch) currentTakenCourses(ctx contractapi.TransactionContextInterface) ([]*TakenCourse, error) {
+	var courses []*TakenCourse
+
+	entries, err := getHEIRecordEntries(ctx, search.hei, "TakenCourse")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+
+		var course TakenCourse
+		err = decodeRecord(ctx, entry, &course)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
+		}
+		courses = append(courses, &course)
+	}
+
+	return courses, nil
+}
+
+// studentLess orders two students by a sort field, then by student ID and hash value
+func studentLess(a *StudentInfo, b *StudentInfo, sortBy string) bool {
+	var first, second string
+
+	switch sortBy {
+	case "student_surname":
+		first, second = a.StudentSurname, b.StudentSurname
+	case "registration_date":
+		first, second = sortableDate(a.RegistrationDate), sortableDate(b.RegistrationDate)
+	case "department":
+		first, second = a.Department, b.Department
+	case "program_type":
+		first, second = a.ProgramType, b.ProgramType
+	}
+
+	if first != second {
+		return first < second
+	}
+
+	if a.StudentID != b.StudentID {
+		return a.StudentID < b.StudentID
+	}
+
+	return a.HashValue < b.HashValue
+}
+
+// studentSortKey returns the fields of a student that studentLess orders by, which the bookmark of a search holds
+func studentSortKey(student *StudentInfo, sortBy string) *StudentInfo {
+	key := StudentInfo{StudentID: student.StudentID, HashValue: student.HashValue}
+
+	switch sortBy {
+	case "student_surname":
+		key.StudentSurname = student.StudentSurname
+	case "registration_date":
+		key.RegistrationDate = student.RegistrationDate
+	case "department":
+		key.Department = student.Department
+	case "program_type":
+		key.ProgramType = student.ProgramType
+	}
+
+	return &key
+}
+
+// sortableDate returns a date in ISO-8601, or as it is when it is not a valid date
+func sortableDate(value string) string {
+	date, err := normalizeDate(value)
+	if err != nil {
+		return value
+	}
+	return date
+}
+
+// takenCourseLess orders two taken courses by a sort field, then by student ID, course code and hash value
+func takenCourseLess(a *TakenCourse, b *TakenCourse, sortBy string) bool {
+	switch sortBy {
+	case "course_code":
+		if a.CourseCode != b.CourseCode {
+			return a.CourseCode < b.CourseCode
+		}
+	case "grade":
+		if a.Grade != b.Grade {
+			return a.Grade < b.Grade
+		}
+	case "point":
+		if comparison := a.Point.rat().Cmp(b.Point.rat()); comparison != 0 {
+			return comparison < 0
+		}
+	case "taken_semester":
+		if a.TakenSemester != b.TakenSemester {
+			return a.TakenSemester < b.TakenSemester
+		}
+	case "term":
+		if a.Term != b.Term {
+			return a.Term < b.Term
+		}
+	}
+
+	if a.StudentID != b.StudentID {
+		return a.StudentID < b.StudentID
+	}
+
+	if a.CourseCode != b.CourseCode {
+		return a.CourseCode < b.CourseCode
+	}
+
+	return a.HashValue < b.HashValue
+}
+
+// takenCourseSortKey returns the fields of a taken course that takenCourseLess orders by, which the bookmark of a search holds
+func takenCourseSortKey(course *TakenCourse, sortBy string) *TakenCourse {
+	key := TakenCourse{StudentID: course.StudentID, CourseCode: course.CourseCode, HashValue: course.HashValue}
+
+	switch sortBy {
+	case "grade":
+		key.Grade = course.Grade
+	case "point":
+		key.Point = course.Point
+	case "taken_semester":
+		key.TakenSemester = course.TakenSemester
+	case "term":
+		key.Term = course.Term
+	}
+
+	return &key
+}
+
+// searchPageBounds cuts one page out of the results sorted by before. The bookmark is the JSON of the sort key, given by keyOf, of the last
+// record of the previous page, empty for the first page; the page starts at the first result that follows it. It returns the bounds of
+// the page and the bookmark of the next page, empty when the page is the last one.
+func searchPageBounds[T any](found []*T, pageSize int32, bookmark string, before func(a *T, b *T) bool, keyOf func(record *T) *T) (int, int, string, error) {
+	err := validatePageSize(pageSize)
+	if err != nil {
+		return 0, 0, "", err
+	}
+
+	start := 0
+	if bookmark != "" {
+		var last T
+		err = json.Unmarshal([]byte(bookmark), &last)
+		if err != nil {
+			return 0, 0, "", fmt.Errorf("invalid bookmark %q, expected the bookmark returned with the previous page", bookmark)
+		}
+
+		start = sort.Search(len(found), func(i int) bool { return before(&last, found[i]) })
+	}
+
+	end := start + int(pageSize)
+	if end >= len(found) {
+		return start, len(found), "", nil
+	}
+
+	jsonKey, err := json.Marshal(keyOf(found[end-1]))
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	return start, end, string(jsonKey), nil
+}
@@ -0,0 +1,234 @@
+package chaincodeTranscript
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// searchLedger holds students of two departments, one of them stored with a DD.MM.YYYY registration date by an earlier version of the
+// chaincode, and their taken courses
+func searchLedger(t *testing.T) *testLedger {
+	ledger := newTestLedger(t)
+
+	ledger.addFaculty()
+	ledger.addDepartment("CENG", "Department of Computer Engineering", "COMP")
+	ledger.addDepartment("IE", "Department of Industrial Engineering", "IE")
+
+	ledger.addStudent(190908801, "Selvi", "Department of Computer Engineering", "2022-09-02")
+	ledger.addStudent(190908802, "Demir", "Department of Industrial Engineering", "2021-09-10")
+	ledger.addStudent(190908803, "Kaya", "Department of Computer Engineering", "2023-09-15")
+
+	legacy := StudentInfo{Faculty: "Faculty of Engineering and Architecture", Department: "Department of Computer Engineering", StudentID: 190908804,
+		StudentSurname: "Arslan", StudentName: "Test", NationalID: "10000000000", RegistrationDate: "01.10.2022", RegistrationType: "Major / OSYM",
+		ProgramType: "Undergraduate"}
+	legacy.HashValue = StructToMD5(legacy)
+	ledger.putLegacyRecord(legacy.StudentID, "StudentInfo", legacy.HashValue, legacy)
+
+	ledger.addTakenCourse(190908801, "COMP1001", "AA", "12", 1)
+	ledger.addTakenCourse(190908802, "COMP1001", "CC", "6", 1)
+	ledger.addTakenCourse(190908803, "COMP2004", "AA", "12", 1)
+	ledger.addTakenCourse(190908804, "COMP1001", "BB", "9", 1)
+
+	return ledger
+}
+
+func TestSearchStudents(t *testing.T) {
+	ledger := searchLedger(t)
+
+	tests := []struct {
+		name       string
+		filter     string
+		sortBy     string
+		descending bool
+		want       []int // Student IDs in the order listed
+		wantErr    string
+	}{
+		{name: "no filter", want: []int{190908801, 190908802, 190908803, 190908804}},
+		{name: "department code", filter: `{"department":"CENG"}`, want: []int{190908801, 190908803, 190908804}},
+		{name: "registered from, with a DD.MM.YYYY date stored", filter: `{"registered_from":"2022-09-15"}`, want: []int{190908803, 190908804}},
+		{name: "registered to, given as DD.MM.YYYY", filter: `{"registered_to":"30.09.2022"}`, want: []int{190908801, 190908802}},
+		{name: "registration date range", filter: `{"registered_from":"2022-01-01","registered_to":"2022-12-31"}`, want: []int{190908801, 190908804}},
+		{name: "sorted by registration date", sortBy: "registration_date", want: []int{190908802, 190908801, 190908804, 190908803}},
+		{name: "sorted by surname, descending", sortBy: "student_surname", descending: true, want: []int{190908801, 190908803, 190908802, 190908804}},
+		{name: "taken course and grades", filter: `{"course_code":"COMP1001","grades":["AA","BB"]}`, want: []int{190908801, 190908804}},
+		{name: "student and course fields", filter: `{"department":"CENG","grades":["AA"]}`, want: []int{190908801, 190908803}},
+		{name: "unknown filter field", filter: `{"faculty_name":"FEA"}`, wantErr: "invalid search filter"},
+		{name: "empty date range", filter: `{"registered_from":"2023-01-01","registered_to":"2022-01-01"}`, wantErr: "the registration date range is empty"},
+		{name: "unknown sort field", sortBy: "national_id", wantErr: "unknown sort field"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := ledger.contract.SearchStudents(ledger.ctx(), testHEI, test.filter, test.sortBy, test.descending, 10, "")
+			if checkError(t, err, test.wantErr) {
+				return
+			}
+
+			got := []int{}
+			for _, student := range page.Records {
+				got = append(got, student.StudentID)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got the students %v, want %v", got, test.want)
+			}
+			if page.TotalCount != int32(len(test.want)) || page.Bookmark != "" {
+				t.Errorf("got the total count %d and the bookmark %q, want %d and none", page.TotalCount, page.Bookmark, len(test.want))
+			}
+		})
+	}
+}
+
+// A stored registration date that is not a date leaves the student out of a search by registration date, which reports the student,
+// instead of being compared as a string or failing the search
+func TestSearchStudentsReportsAnInvalidRegistrationDate(t *testing.T) {
+	ledger := searchLedger(t)
+
+	broken := StudentInfo{Department: "Department of Computer Engineering", StudentID: 190908805, StudentSurname: "Broken", RegistrationDate: "2022/09/02"}
+	broken.HashValue = StructToMD5(broken)
+	ledger.putLegacyRecord(broken.StudentID, "StudentInfo", broken.HashValue, broken)
+
+	tests := []struct {
+		name        string
+		filter      string
+		wantCount   int32
+		wantInvalid []int
+	}{
+		{name: "registration date filter", filter: `{"registered_from":"2022-01-01"}`, wantCount: 3, wantInvalid: []int{190908805}},
+		{name: "no registration date filter", filter: `{"department":"CENG"}`, wantCount: 4},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := ledger.contract.SearchStudents(ledger.ctx(), testHEI, test.filter, "", false, 10, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if page.TotalCount != test.wantCount || !reflect.DeepEqual(page.InvalidRegistrationDates, test.wantInvalid) {
+				t.Errorf("got %d students and the invalid registration dates of %v, want %d and %v", page.TotalCount,
+					page.InvalidRegistrationDates, test.wantCount, test.wantInvalid)
+			}
+		})
+	}
+
+	courses, err := ledger.contract.SearchTakenCourses(ledger.ctx(), testHEI, `{"registered_to":"2030-01-01"}`, "", false, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(courses.InvalidRegistrationDates, []int{190908805}) {
+		t.Errorf("got the invalid registration dates of %v in the taken course search, want [190908805]", courses.InvalidRegistrationDates)
+	}
+}
+
+func TestSearchTakenCourses(t *testing.T) {
+	ledger := searchLedger(t)
+
+	tests := []struct {
+		name   string
+		filter string
+		sortBy string
+		want   []int // Student IDs of the courses in the order listed
+	}{
+		{name: "course code", filter: `{"course_code":"COMP1001"}`, want: []int{190908801, 190908802, 190908804}},
+		{name: "sorted by grade", sortBy: "grade", want: []int{190908801, 190908803, 190908804, 190908802}},
+		{name: "student fields", filter: `{"registered_from":"2022-09-15","grades":["AA","BB"]}`, want: []int{190908803, 190908804}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := ledger.contract.SearchTakenCourses(ledger.ctx(), testHEI, test.filter, test.sortBy, false, 10, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := []int{}
+			for _, course := range page.Records {
+				got = append(got, course.StudentID)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got the courses of the students %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// The pages of a search are cut after the last record of the previous page, which the bookmark names by its sort field, student ID and
+// hash value, so a student inserted before it between two pages neither repeats nor skips a student on the next page
+func TestSearchStudentsPages(t *testing.T) {
+	ledger := searchLedger(t)
+
+	tests := []struct {
+		name       string
+		sortBy     string
+		descending bool
+		want       []int
+	}{
+		{name: "student ID", want: []int{190908801, 190908802, 190908803, 190908804}},
+		{name: "surname, descending", sortBy: "student_surname", descending: true, want: []int{190908801, 190908803, 190908802, 190908804}},
+		{name: "registration date", sortBy: "registration_date", want: []int{190908802, 190908801, 190908804, 190908803}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			students := allPages(t, func(bookmark string) ([]*StudentInfo, string, error) {
+				page, err := ledger.contract.SearchStudents(ledger.ctx(), testHEI, "", test.sortBy, test.descending, 3, bookmark)
+				if err != nil {
+					return nil, "", err
+				}
+				return page.Records, page.Bookmark, nil
+			})
+
+			var got []int
+			for _, student := range students {
+				got = append(got, student.StudentID)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got the students %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	first, err := ledger.contract.SearchStudents(ledger.ctx(), testHEI, "", "", false, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ledger.addStudent(190908800, "Yilmaz", "Department of Computer Engineering", "2022-09-02")
+
+	second, err := ledger.contract.SearchStudents(ledger.ctx(), testHEI, "", "", false, 2, first.Bookmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Records[0].StudentID != 190908803 {
+		t.Errorf("got the student %d first on the next page, want 190908803", second.Records[0].StudentID)
+	}
+
+	_, err = ledger.contract.SearchStudents(ledger.ctx(), testHEI, "", "", false, 2, "next")
+	if err == nil || !strings.Contains(err.Error(), "invalid bookmark") {
+		t.Errorf("got the error %v, want an invalid bookmark", err)
+	}
+}
+
+// The pages of a taken course search sorted by point follow the numeric order of the points across the pages
+func TestSearchTakenCoursesPages(t *testing.T) {
+	ledger := searchLedger(t)
+
+	courses := allPages(t, func(bookmark string) ([]*TakenCourse, string, error) {
+		page, err := ledger.contract.SearchTakenCourses(ledger.ctx(), testHEI, "", "point", true, 1, bookmark)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Records, page.Bookmark, nil
+	})
+
+	var got []int
+	for _, course := range courses {
+		got = append(got, course.StudentID)
+	}
+
+	if want := []int{190908803, 190908801, 190908804, 190908802}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got the courses of the students %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,256 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Academic standing: probation when the CGPA falls below the HEI's thresholds, evaluated whenever a record it depends on changes
+// *
+// ------------------------------------------------------------------------------------------------------
+
+const (
+	goodStanding      = "Good Standing"
+	probationStanding = "Probation" // Sınamalı
+)
+
+// StandingRule puts a student on probation when the CGPA at the end of a semester from AfterSemester on is below MinCGPA.
+// At each semester the rule with the highest AfterSemester not beyond it applies.
+type StandingRule struct {
+	AfterSemester int     `json:"after_semester"`
+	MinCGPA       float64 `json:"min_cgpa"`
+}
+
+// defaultStandingRules: a CGPA below 1.80 after the second semester and below 2.00 from the fourth semester on means probation
+var defaultStandingRules = []StandingRule{{AfterSemester: 2, MinCGPA: 1.80}, {AfterSemester: 4, MinCGPA: 2.00}}
+
+// TermStanding is the academic standing of a student at the end of a semester
+type TermStanding struct {
+	Semester int     `json:"semester"`
+	CGPA     float64 `json:"cgpa"`
+	Standing string  `json:"standing"`
+}
+
+// StudentStanding is stored once per student under the "standing" composite key and rewritten on every evaluation
+type StudentStanding struct {
+	StudentID      int            `json:"student_id"`
+	Department     string         `json:"department"`
+	DepartmentCode string         `json:"department_code,omitempty" metadata:",optional"`
+	Standing       string         `json:"standing"`     // Standing after the last semester with courses
+	EvaluatedAt    string         `json:"evaluated_at"` // Transaction timestamp of the evaluation, RFC3339
+	History        []TermStanding `json:"history"`      // Standing at the end of each semester, in semester order
+}
+
+func (Transcript *SmartContract) SetStandingRules(ctx contractapi.TransactionContextInterface, owner string, rules []StandingRule) (bool, error) {
+	if len(rules) == 0 {
+		return false, fmt.Errorf("the standing rules must not be empty")
+	}
+
+	seen := make(map[int]bool)
+	for _, rule := range rules {
+		if rule.AfterSemester < 1 || rule.MinCGPA <= 0 {
+			return false, fmt.Errorf("a standing rule needs a semester from 1 on and a positive CGPA: %d, %v", rule.AfterSemester, rule.MinCGPA)
+		}
+		if seen[rule.AfterSemester] {
+			return false, fmt.Errorf("there is more than one standing rule after the semester %d", rule.AfterSemester)
+		}
+		seen[rule.AfterSemester] = true
+	}
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	config.StandingRules = rules
+
+	return true, putHEIConfig(ctx, config)
+}
+
+// EvaluateStudentStanding evaluates and stores a student's standing again, e.g. after the HEI changed its standing rules
+func (Transcript *SmartContract) EvaluateStudentStanding(ctx contractapi.TransactionContextInterface, owner string, studentID string) (*StudentStanding, error) {
+	transcript, err := Transcript.buildStudentTranscript(ctx, owner, studentID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if transcript.Completeness.MissingStudentInfo {
+		return nil, fmt.Errorf("there is not a student info record of the student %s", studentID)
+	}
+
+	return putStudentStanding(ctx, owner, transcript)
+}
+
+func (Transcript *SmartContract) Get_Student_Standing(ctx contractapi.TransactionContextInterface, hei string, studentID string) (*StudentStanding, error) {
+	standingKey, err := ctx.GetStub().CreateCompositeKey("standing", []string{hei, studentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonData, err := ctx.GetStub().GetState(standingKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if jsonData == nil {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	var standing StudentStanding
+	err = json.Unmarshal(jsonData, &standing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+	}
+
+	return &standing, nil
+}
+
+// GetProbationStudents lists the stored standings of a department's students currently on probation; the department is given by its registered code or by its name
+func (Transcript *SmartContract) GetProbationStudents(ctx contractapi.TransactionContextInterface, hei string, department string) ([]*StudentStanding, error) {
+	var standings []*StudentStanding
+
+	organization, err := loadOrganization(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	inDepartment := organization.departmentFilter(department)
+
+	err = getByPartialCompositeKey(ctx, "standing", []string{hei}, func(value []byte) error {
+		var standing StudentStanding
+		err := json.Unmarshal(value, &standing)
+		if standing.Standing == probationStanding && inDepartment(standing.DepartmentCode, standing.Department) {
+			standings = append(standings, &standing)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(standings) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return standings, nil
+}
+
+// standingOf returns the standing at the end of a semester with the given CGPA
+func (config *HEIConfig) standingOf(semester int, cgpa float64) string {
+	rules := config.StandingRules
+	if len(rules) == 0 {
+		rules = defaultStandingRules
+	}
+
+	var applying *StandingRule
+	for index := range rules {
+		rule := &rules[index]
+		if rule.AfterSemester <= semester && (applying == nil || rule.AfterSemester > applying.AfterSemester) {
+			applying = rule
+		}
+	}
+
+	if applying != nil && cgpa < applying.MinCGPA {
+		return probationStanding
+	}
+
+	return goodStanding
+}
+
+// pendingRecords are the records of a student written by the running transaction, which the transcript built from the world state
+// cannot see yet. A StudentInfo record replaces the current one; the course records are added to the current ones.
+type pendingRecords struct {
+	infoStudent *StudentInfo
+	infoCourse  *CourseInfo
+	courseTaken *TakenCourse
+}
+
+// evaluateStandingWith stores the student's standing with the records written by the running transaction, whenever a record the
+// CGPA or the department depends on changes. A student without a student info record yet has no standing to evaluate; it is
+// evaluated when the record is inserted.
+func (Transcript *SmartContract) evaluateStandingWith(ctx contractapi.TransactionContextInterface, owner string, config *HEIConfig, scales gradeLookup,
+	studentID string, pending pendingRecords) error {
+
+	infoStudent, infoCourses, coursesTaken, err := readStudentRecords(ctx, owner, studentID)
+	if err != nil {
+		return err
+	}
+
+	if pending.infoStudent != nil {
+		infoStudent = pending.infoStudent
+	}
+
+	if infoStudent == nil {
+		return nil
+	}
+
+	if pending.infoCourse != nil {
+		infoCourses = append(infoCourses, pending.infoCourse)
+	}
+
+	if pending.courseTaken != nil {
+		coursesTaken = append(coursesTaken, pending.courseTaken)
+	}
+
+	transcript, err := Transcript.assembleTranscript(ctx, currentState{}, owner, infoStudent, infoCourses, coursesTaken, config, scales, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = putStudentStanding(ctx, owner, transcript)
+	return err
+}
+
+// evaluateStanding is evaluateStandingWith for a transaction that has not loaded the HEI's configuration and grading scales
+func (Transcript *SmartContract) evaluateStanding(ctx contractapi.TransactionContextInterface, owner string, studentID string, pending pendingRecords) error {
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return err
+	}
+
+	scales, err := loadGradingScales(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	return Transcript.evaluateStandingWith(ctx, owner, config, scales, studentID, pending)
+}
+
+func putStudentStanding(ctx contractapi.TransactionContextInterface, owner string, transcript *StudentTranscript) (*StudentStanding, error) {
+	student := transcript.InfoStudent
+	standing := StudentStanding{StudentID: student.StudentID, Department: student.Department, DepartmentCode: student.DepartmentCode,
+		Standing: goodStanding, History: []TermStanding{}}
+
+	for _, block := range transcript.Terms {
+		standing.History = append(standing.History, TermStanding{Semester: block.Semester, CGPA: block.Cumulative.GPA, Standing: block.Standing})
+		standing.Standing = block.Standing
+	}
+
+	var err error
+	standing.EvaluatedAt, err = txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	standingKey, err := ctx.GetStub().CreateCompositeKey("standing", []string{owner, strconv.Itoa(student.StudentID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	jsonStanding, err := json.Marshal(standing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(standingKey, jsonStanding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put academic standing to world state. %v", err)
+	}
+
+	return &standing, nil
+}
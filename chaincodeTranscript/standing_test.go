@@ -0,0 +1,109 @@
+package chaincodeTranscript
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestStandingEvaluatedOnEveryRecordItDependsOn(t *testing.T) {
+	const department = "Department of Computer Engineering"
+
+	tests := []struct {
+		name           string
+		insert         func(ledger *testLedger) // Inserts the records, the last of which is evaluated over
+		wantStanding   string                   // Empty for no stored standing
+		wantDepartment string
+	}{
+		{
+			name: "taken course inserted last",
+			insert: func(ledger *testLedger) {
+				ledger.addStudent(190908809, "Selvi", department, "2022-09-02")
+				ledger.addCourseInfo(190908809, "COMP2004", 6, 3)
+				ledger.addTakenCourse(190908809, "COMP2004", "FF", "0", 2)
+			},
+			wantStanding:   probationStanding,
+			wantDepartment: department,
+		},
+		{
+			name: "course info inserted last",
+			insert: func(ledger *testLedger) {
+				ledger.addStudent(190908809, "Selvi", department, "2022-09-02")
+				ledger.addTakenCourse(190908809, "COMP2004", "FF", "0", 2)
+				ledger.addCourseInfo(190908809, "COMP2004", 6, 3)
+			},
+			wantStanding:   probationStanding,
+			wantDepartment: department,
+		},
+		{
+			name: "student info inserted last",
+			insert: func(ledger *testLedger) {
+				ledger.addCourseInfo(190908809, "COMP2004", 6, 3)
+				ledger.addTakenCourse(190908809, "COMP2004", "FF", "0", 2)
+				ledger.addStudent(190908809, "Selvi", department, "2022-09-02")
+			},
+			wantStanding:   probationStanding,
+			wantDepartment: department,
+		},
+		{
+			name: "no student info",
+			insert: func(ledger *testLedger) {
+				ledger.addCourseInfo(190908809, "COMP2004", 6, 3)
+				ledger.addTakenCourse(190908809, "COMP2004", "FF", "0", 2)
+			},
+		},
+		{
+			name: "student info updated",
+			insert: func(ledger *testLedger) {
+				ledger.addStudent(190908809, "Selvi", department, "2022-09-02")
+				ledger.addCourse(190908809, "COMP2004", "AA", "12", 2)
+				err := ledger.updateStudentInfo(190908809, "Department of Industrial Engineering", "Test", 0, "Transferred to another department")
+				if err != nil {
+					ledger.t.Fatal(err)
+				}
+			},
+			wantStanding:   goodStanding,
+			wantDepartment: "Department of Industrial Engineering",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			test.insert(ledger)
+
+			standing, err := ledger.contract.Get_Student_Standing(ledger.ctx(), testHEI, "190908809")
+			if test.wantStanding == "" {
+				if err == nil {
+					t.Fatalf("got the standing %+v, want none", standing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if standing.Standing != test.wantStanding || standing.Department != test.wantDepartment {
+				t.Errorf("got %s in %s, want %s in %s", standing.Standing, standing.Department, test.wantStanding, test.wantDepartment)
+			}
+		})
+	}
+}
+
+// A transcript that cannot be constructed fails the insert instead of leaving the stored standing out of date
+func TestStandingEvaluationReportsABrokenTranscript(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+	ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+		meta := MetaInfo{Owner: testHEI, StudentID: "190908809", Relation: "TakenCourse", HashValue: "0123456789abcdef0123456789abcdef"}
+		return putMetaInfo(ctx, meta, nil)
+	})
+
+	err := ledger.trySubmit(func(ctx contractapi.TransactionContextInterface) error {
+		return errorOf(ledger.contract.InsertNewRecordTakenCourse(ctx, testHEI, 190908809, "COMP2004", "FF", "0", 2))
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to construct the transcript") {
+		t.Fatalf("got the error %v, want the transcript error", err)
+	}
+}
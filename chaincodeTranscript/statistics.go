@@ -0,0 +1,150 @@
+package chaincodeTranscript
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Course statistics: the grade distribution of a course in an academic term
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// defaultMinCohortSize is the fewest students a course statistic is given for, so that no student's grade can be inferred from it
+const defaultMinCohortSize = 5
+
+// CourseStatistics is the grade distribution of a course in a term, built from the current TakenCourse records taken in its offerings.
+// A TakenCourse inserted without an offering has no term and is left out of every statistic. Every figure counts each student once, by
+// the record with the highest point when a student has more than one in the term. When fewer students than the HEI's minimum cohort
+// size took the course, which includes a term in which nobody took it, only the minimum is given and Suppressed is set.
+type CourseStatistics struct {
+	CourseCode    string       `json:"course_code"`
+	Term          string       `json:"term"`
+	MinCohortSize int          `json:"min_cohort_size"`
+	Suppressed    bool         `json:"suppressed"`
+	StudentCount  int          `json:"student_count"`
+	GradeCounts   []GradeCount `json:"grade_counts"` // The grades of the scale in force, in its order, then any other grade given
+	MeanPoint     float64      `json:"mean_point"`   // Mean of the points of the students' records
+	PassRate      float64      `json:"pass_rate"`    // Percentage of the students whose grade passes the course
+}
+
+// GradeCount is the number of students given a grade
+type GradeCount struct {
+	Grade string `json:"grade"`
+	Count int    `json:"count"`
+}
+
+// SetMinCohortSize sets the fewest students a course statistic is given for
+func (Transcript *SmartContract) SetMinCohortSize(ctx contractapi.TransactionContextInterface, owner string, size int) (bool, error) {
+	if size < 1 {
+		return false, fmt.Errorf("the minimum cohort size must be positive, got %d", size)
+	}
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	config.MinCohortSize = size
+
+	return true, putHEIConfig(ctx, config)
+}
+
+// GetCourseStatistics computes the grade distribution of a course in an academic term, e.g. 2023-2024 Fall. Only courses taken in a
+// course offering, inserted with InsertNewRecordTakenCourseInOffering, carry an academic term and are counted.
+func (Transcript *SmartContract) GetCourseStatistics(ctx contractapi.TransactionContextInterface, hei string, courseCode string, term string) (*CourseStatistics, error) {
+	if courseCode == "" || term == "" {
+		return nil, fmt.Errorf("the course code and the term must not be empty")
+	}
+
+	config, err := loadHEIConfig(ctx, hei)
+	if err != nil {
+		return nil, err
+	}
+
+	scales, err := loadGradingScales(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	statistics := CourseStatistics{CourseCode: courseCode, Term: term, MinCohortSize: config.minCohortSize(), GradeCounts: []GradeCount{}}
+
+	entries, err := getHEIRecordEntries(ctx, hei, "TakenCourse")
+	if err != nil {
+		return nil, err
+	}
+
+	// The record of each student with the highest point, then the lowest hash value, so that the choice does not depend on the key order
+	students := make(map[int]*TakenCourse)
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+
+		var course TakenCourse
+		err = decodeRecord(ctx, entry, &course)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
+		}
+
+		if course.CourseCode != courseCode || course.Term != term {
+			continue
+		}
+
+		chosen, ok := students[course.StudentID]
+		if ok {
+			comparison := course.Point.rat().Cmp(chosen.Point.rat())
+			if comparison < 0 || (comparison == 0 && course.HashValue > chosen.HashValue) {
+				continue
+			}
+		}
+		students[course.StudentID] = &course
+	}
+
+	if len(students) < statistics.MinCohortSize {
+		statistics.Suppressed = true
+		return &statistics, nil
+	}
+
+	statistics.StudentCount = len(students)
+
+	counts := make(map[string]int)
+	var passed int
+	points := new(big.Rat)
+
+	for _, course := range students {
+		counts[course.Grade]++
+
+		if scales.isPassing(course.Grade) {
+			passed++
+		}
+
+		points.Add(points, course.Point.rat())
+	}
+
+	for _, definition := range scales.inForce().Grades {
+		statistics.GradeCounts = append(statistics.GradeCounts, GradeCount{Grade: definition.Code, Count: counts[definition.Code]})
+		delete(counts, definition.Code)
+	}
+
+	var others []string
+	for grade := range counts {
+		others = append(others, grade)
+	}
+	sort.Strings(others)
+
+	for _, grade := range others {
+		statistics.GradeCounts = append(statistics.GradeCounts, GradeCount{Grade: grade, Count: counts[grade]})
+	}
+
+	meanPoint, _ := points.Quo(points, big.NewRat(int64(len(students)), 1)).Float64()
+	statistics.MeanPoint = roundTo2(meanPoint)
+	statistics.PassRate = roundTo2(100 * float64(passed) / float64(len(students)))
+
+	return &statistics, nil
+}
@@ -0,0 +1,120 @@
+package chaincodeTranscript
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// statisticsLedger holds six students who took COMP2004 in its 2023-2024 Fall offering, one of them with a second, lower record in it,
+// one student who took it in another term and one whose TakenCourse was inserted without an offering
+func statisticsLedger(t *testing.T) *testLedger {
+	ledger := newTestLedger(t)
+
+	for _, term := range []string{"2023-2024 Fall", "2024-2025 Fall"} {
+		ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(ledger.contract.InsertNewRecordCourseOffering(ctx, testHEI, "COMP2004", term, "01", "T-1029", "Dr. Ayse Demir", "English",
+				"Face-to-face"))
+		})
+	}
+
+	inOffering := []struct {
+		studentID int
+		grade     string
+		point     string
+		term      string
+	}{
+		{190908801, "AA", "12", "2023-2024 Fall"},
+		{190908802, "AA", "12", "2023-2024 Fall"},
+		{190908803, "BB", "9", "2023-2024 Fall"},
+		{190908803, "FF", "0", "2023-2024 Fall"},
+		{190908804, "CC", "6", "2023-2024 Fall"},
+		{190908805, "FF", "0", "2023-2024 Fall"},
+		{190908806, "S", "0", "2023-2024 Fall"},
+		{190908807, "FF", "0", "2024-2025 Fall"},
+	}
+
+	for _, course := range inOffering {
+		ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+			return errorOf(ledger.contract.InsertNewRecordTakenCourseInOffering(ctx, testHEI, course.studentID, "COMP2004", course.grade, course.point, 3,
+				course.term, "01"))
+		})
+	}
+
+	ledger.addTakenCourse(190908808, "COMP2004", "FF", "0", 3)
+
+	return ledger
+}
+
+func TestGetCourseStatistics(t *testing.T) {
+	tests := []struct {
+		name           string
+		minCohortSize  int // Set when positive
+		term           string
+		wantSuppressed bool
+		wantStudents   int
+		wantGrades     map[string]int // Grades given, the other grades of the scale have a zero count
+		wantMeanPoint  float64
+		wantPassRate   float64
+		wantErr        string
+	}{
+		{name: "grades of the offering's term", term: "2023-2024 Fall", wantStudents: 6, wantGrades: map[string]int{"AA": 2, "BB": 1, "CC": 1, "FF": 1, "S": 1},
+			wantMeanPoint: 6.5, wantPassRate: 83.33},
+		{name: "cohort below the minimum size", minCohortSize: 7, term: "2023-2024 Fall", wantSuppressed: true},
+		{name: "term of a single student", term: "2024-2025 Fall", wantSuppressed: true},
+		{name: "term of a single student, minimum size 1", minCohortSize: 1, term: "2024-2025 Fall", wantStudents: 1, wantGrades: map[string]int{"FF": 1}},
+		{name: "term without records", term: "2022-2023 Spring", wantSuppressed: true},
+		{name: "term without records, minimum size 1", minCohortSize: 1, term: "2022-2023 Spring", wantSuppressed: true},
+		{name: "no term", wantErr: "must not be empty"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := statisticsLedger(t)
+
+			if test.minCohortSize > 0 {
+				ledger.submit(func(ctx contractapi.TransactionContextInterface) error {
+					return errorOf(ledger.contract.SetMinCohortSize(ctx, testHEI, test.minCohortSize))
+				})
+			}
+
+			statistics, err := ledger.contract.GetCourseStatistics(ledger.ctx(), testHEI, "COMP2004", test.term)
+			if checkError(t, err, test.wantErr) {
+				return
+			}
+
+			if statistics.Suppressed != test.wantSuppressed {
+				t.Fatalf("got suppressed: %v, want %v", statistics.Suppressed, test.wantSuppressed)
+			}
+			if test.wantSuppressed {
+				if statistics.StudentCount != 0 || len(statistics.GradeCounts) != 0 {
+					t.Errorf("a suppressed statistic gives %d students and the grade counts %v", statistics.StudentCount, statistics.GradeCounts)
+				}
+				return
+			}
+
+			if statistics.StudentCount != test.wantStudents {
+				t.Errorf("got %d students, want %d", statistics.StudentCount, test.wantStudents)
+			}
+
+			grades := make(map[string]int)
+			for _, count := range statistics.GradeCounts {
+				if count.Count > 0 {
+					grades[count.Grade] = count.Count
+				}
+			}
+			if !reflect.DeepEqual(grades, test.wantGrades) {
+				t.Errorf("got the grades %v, want %v", grades, test.wantGrades)
+			}
+			if len(statistics.GradeCounts) != len(defaultGradingScale.Grades) {
+				t.Errorf("got %d grade counts, want one per grade of the scale", len(statistics.GradeCounts))
+			}
+
+			if statistics.MeanPoint != test.wantMeanPoint || statistics.PassRate != test.wantPassRate {
+				t.Errorf("got the mean point %v and the pass rate %v, want %v and %v", statistics.MeanPoint, statistics.PassRate,
+					test.wantMeanPoint, test.wantPassRate)
+			}
+		})
+	}
+}
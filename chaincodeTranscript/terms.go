@@ -0,0 +1,79 @@
+package chaincodeTranscript
+
+import "sort"
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Term blocks: the transcript laid out term by term, as on the transcripts of Turkish universities
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// TermBlock lists the courses taken in one semester, sorted by course code, with the term totals and the running cumulative totals
+type TermBlock struct {
+	Semester   int                     `json:"semester"`
+	Term       string                  `json:"term,omitempty" metadata:",optional"` // Academic term of the semester's course offerings, e.g. 2023-2024 Fall
+	Courses    []CombinedCourseRecords `json:"courses"`
+	Totals     GPASummary              `json:"totals"`                               // Credits, ECTS, points and GPA of the term
+	Cumulative GPASummary              `json:"cumulative"`                           // Credits, ECTS, points and CGPA up to and including the term
+	Honor      string                  `json:"honor,omitempty" metadata:",optional"` // Honor or High Honor status of the term, following the HEI's honor rules
+	Standing   string                  `json:"standing"`                             // Academic standing at the end of the term, following the HEI's standing rules
+}
+
+// buildTermBlocks sorts the courses by semester and course code, groups them into term blocks and returns the blocks with the
+// cumulative totals of all terms. A term GPA counts every course taken in the term; in the cumulative totals a repeated course
+// counts only with its latest attempt. The honor status and the academic standing
+// of each term are evaluated with the HEI's rules.
+func buildTermBlocks(courses []CombinedCourseRecords, config *HEIConfig, scales gradeLookup) ([]TermBlock, GPASummary) {
+	var cumulative GPASummary
+	weighting := config.Weighting
+	blocks := []TermBlock{}
+	latestAttempts := make(map[string]CombinedCourseRecords)
+	var courseCodes []string // Keys of latestAttempts
+
+	sort.SliceStable(courses, func(i, j int) bool {
+		if courses[i].TakenSemester != courses[j].TakenSemester {
+			return courses[i].TakenSemester < courses[j].TakenSemester
+		}
+		return courses[i].CourseCode < courses[j].CourseCode
+	})
+
+	for _, course := range courses {
+		if len(blocks) == 0 || blocks[len(blocks)-1].Semester != course.TakenSemester {
+			blocks = append(blocks, TermBlock{Semester: course.TakenSemester, Courses: []CombinedCourseRecords{}})
+		}
+
+		block := &blocks[len(blocks)-1]
+		block.Courses = append(block.Courses, course)
+		block.Totals.add(course, weighting, scales)
+		if block.Term == "" {
+			block.Term = course.Term
+		}
+	}
+
+	for index := range blocks {
+		block := &blocks[index]
+		block.Totals.finish(weighting)
+		block.Honor = config.honorRules().honorOf(block, weighting, scales)
+
+		for _, course := range block.Courses {
+			if _, ok := latestAttempts[course.CourseCode]; !ok {
+				courseCodes = append(courseCodes, course.CourseCode)
+			}
+			latestAttempts[course.CourseCode] = course
+		}
+
+		// The points are summed in course code order, since a float sum depends on the order and every peer must compute the same totals
+		sort.Strings(courseCodes)
+
+		cumulative = GPASummary{}
+		for _, courseCode := range courseCodes {
+			cumulative.add(latestAttempts[courseCode], weighting, scales)
+		}
+		cumulative.finish(weighting)
+
+		block.Cumulative = cumulative
+		block.Standing = config.standingOf(block.Semester, cumulative.GPA)
+	}
+
+	return blocks, cumulative
+}
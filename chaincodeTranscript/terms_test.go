@@ -0,0 +1,95 @@
+package chaincodeTranscript
+
+import (
+	"testing"
+)
+
+func TestBuildTermBlocks(t *testing.T) {
+	scales := gradeLookup{&defaultGradingScale}
+
+	tests := []struct {
+		name           string
+		courses        []CombinedCourseRecords
+		wantSemesters  []int
+		wantTermGPAs   []float64
+		wantCumulative []float64 // CGPA at the end of each term
+	}{
+		{
+			name: "one term",
+			courses: []CombinedCourseRecords{
+				{CourseCode: "COMP2004", Grade: "AA", Credit: 3, TakenSemester: 1},
+				{CourseCode: "COMP1001", Grade: "CC", Credit: 3, TakenSemester: 1},
+			},
+			wantSemesters:  []int{1},
+			wantTermGPAs:   []float64{3},
+			wantCumulative: []float64{3},
+		},
+		{
+			name: "repeated course counts with its latest attempt",
+			courses: []CombinedCourseRecords{
+				{CourseCode: "MATH1001", Grade: "FF", Credit: 4, TakenSemester: 1},
+				{CourseCode: "COMP1001", Grade: "BB", Credit: 4, TakenSemester: 1},
+				{CourseCode: "MATH1001", Grade: "AA", Credit: 4, TakenSemester: 3},
+			},
+			wantSemesters:  []int{1, 3},
+			wantTermGPAs:   []float64{1.5, 4},
+			wantCumulative: []float64{1.5, 3.5},
+		},
+		{
+			name: "courses without a GPA weight",
+			courses: []CombinedCourseRecords{
+				{CourseCode: "TURK1001", Grade: "S", Credit: 2, TakenSemester: 2},
+				{CourseCode: "COMP1001", Grade: "BA", Credit: 3, TakenSemester: 2},
+			},
+			wantSemesters:  []int{2},
+			wantTermGPAs:   []float64{3.5},
+			wantCumulative: []float64{3.5},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blocks, _ := buildTermBlocks(test.courses, defaultHEIConfig(testHEI), scales)
+
+			if len(blocks) != len(test.wantSemesters) {
+				t.Fatalf("got %d term blocks, want %d", len(blocks), len(test.wantSemesters))
+			}
+
+			for index, block := range blocks {
+				if block.Semester != test.wantSemesters[index] || block.Totals.GPA != test.wantTermGPAs[index] ||
+					block.Cumulative.GPA != test.wantCumulative[index] {
+					t.Errorf("block %d: semester %d, GPA %v, CGPA %v; want semester %d, GPA %v, CGPA %v", index, block.Semester, block.Totals.GPA,
+						block.Cumulative.GPA, test.wantSemesters[index], test.wantTermGPAs[index], test.wantCumulative[index])
+				}
+			}
+		})
+	}
+}
+
+// The cumulative totals must be the same on every peer, so they must not depend on the order of a map iteration
+func TestBuildTermBlocksIsDeterministic(t *testing.T) {
+	scale := GradingScale{ScaleID: "TENTHS", Grades: []GradeDefinition{
+		{Code: "A", Coefficient: 0.1, Passing: true, CountsInGPA: true},
+		{Code: "B", Coefficient: 0.2, Passing: true, CountsInGPA: true},
+		{Code: "C", Coefficient: 0.3, Passing: true, CountsInGPA: true},
+		{Code: "D", Coefficient: 0.7, Passing: true, CountsInGPA: true},
+	}}
+
+	var courses []CombinedCourseRecords
+	for index, code := range []string{"C01", "C02", "C03", "C04", "C05", "C06", "C07", "C08", "C09", "C10", "C11", "C12"} {
+		courses = append(courses, CombinedCourseRecords{CourseCode: code, Grade: scale.Grades[index%len(scale.Grades)].Code, Credit: 1 + index%3,
+			TakenSemester: 1})
+	}
+
+	var first GPASummary
+	for run := 0; run < 100; run++ {
+		input := append([]CombinedCourseRecords(nil), courses...)
+		_, cumulative := buildTermBlocks(input, defaultHEIConfig(testHEI), gradeLookup{&scale})
+
+		if run == 0 {
+			first = cumulative
+		} else if cumulative != first {
+			t.Fatalf("run %d gave %+v, run 0 gave %+v", run, cumulative, first)
+		}
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
@@ -26,23 +25,114 @@ import (
 // peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["IsRecordExists", "Fenerbahce University", "190908809", "48c4c683034af0c0a03fbda1d9a1f7cd"]}'
 
 // 3- To create new records from student information (StudentInfo), course information (CourseInfo), and results of courses achieved by a student (TakenCourse)
-// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"InsertNewRecordStudentInfo","Args":["Fenerbahce University", "Faculty of Engineering and Architecture", "Department of Computer Engineering", "299799009", "Selvi", "Ahmet", "44262495576", "02.09.2022", "Major / OSYM", "Undergraduate", "2", "3"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"InsertNewRecordStudentInfo","Args":["Fenerbahce University", "Faculty of Engineering and Architecture", "Department of Computer Engineering", "299799009", "Selvi", "Ahmet", "44262495576", "2022-09-02", "Major / OSYM", "Undergraduate", "2", "3"]}'
 // peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"InsertNewRecordTakenCourse","Args":["Fenerbahce University", "299799009", "COMP2004", "BB", "18", "4"]}'
 // peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"InsertNewRecordCourseInfo","Args":["Fenerbahce University", "299799009", "COMP2004", "Database Management Systems", "C", "6", "3"]}'
 
-// 4- To query for fetching a student's relevant records from Hyperledger Fabric CouchDB
+// 4- To query for fetching a student's relevant records from the world state (LevelDB or CouchDB)
 // peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_StudentInfo", "Fenerbahce University", "190908809"]}'
 // peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_CourseInfos", "Fenerbahce University", "190908809"]}'
 // peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_TakenCourses", "Fenerbahce University", "190908809"]}'
 
-// 5- To query for fetching a higher education institution's relevant records from Hyperledger Fabric CouchDB
+// 5- To query for fetching a higher education institution's relevant records from the world state (LevelDB or CouchDB)
 // peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_TakenCourses", "Fenerbahce University"]}'
 // peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_CourseInfos", "Fenerbahce University"]}'
 // peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_StudentInfos", "Fenerbahce University"]}'
 
-// 6- To query for fetching a student's transcript from Hyperledger Fabric CouchDB
+// 6- To query for fetching a student's transcript from the world state (LevelDB or CouchDB)
 // peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"GetStudentTranscript","Args":["Fenerbahce University", "190908809"]}'
 
+// 7- To enroll a student to an additional program (double major, minor, double degree) and attribute a taken course to it
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"InsertNewRecordProgramEnrollment","Args":["Fenerbahce University", "190908809", "IE-MINOR", "Faculty of Engineering and Architecture", "Department of Industrial Engineering", "Undergraduate", "Minor", "Minor / Internal", "2023-09-15", "Active"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"AttributeTakenCourseToProgram","Args":["Fenerbahce University", "190908809", "<hash value of a TakenCourse>", "IE-MINOR"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"UpdateProgramEnrollmentStatus","Args":["Fenerbahce University", "190908809", "IE-MINOR", "Withdrawn", "Withdrew from the minor program"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_ProgramEnrollments", "Fenerbahce University", "190908809"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetStudentProgramTranscript", "Fenerbahce University", "190908809", "IE-MINOR"]}'
+
+// 8- To award a degree, which seals the hash of the transcript at that moment, and to verify it later
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"AwardDegree","Args":["Fenerbahce University", "190908809", "", "Bachelor of Science in Computer Engineering", "2026-06-30", "3.12", "High Honor", "FBU-2026-000123"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_DegreeAwards", "Fenerbahce University", "190908809"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["VerifyDegreeAward", "Fenerbahce University", "FBU-2026-000123"]}'
+
+// 9- To amend a student's personal data: the previous StudentInfo version is kept and marked as superseded
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"UpdateStudentInfo","Args":["Fenerbahce University", "190908809", "Faculty of Engineering and Architecture", "Department of Computer Engineering", "Selvi", "Osman", "44262495576", "2022-09-02", "Major / OSYM", "Undergraduate", "2", "3", "Promoted to the second class"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_StudentInfo_Versions", "Fenerbahce University", "190908809"]}'
+
+// 10- To open a course offering (course, term, section) and to record a taken course that references it
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"InsertNewRecordCourseOffering","Args":["Fenerbahce University", "COMP2004", "2023-2024 Fall", "01", "T-1029", "Dr. Ayse Demir", "English", "Face-to-face"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"InsertNewRecordTakenCourseInOffering","Args":["Fenerbahce University", "299799009", "COMP2004", "BB", "18", "4", "2023-2024 Fall", "01"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_CourseOfferings", "Fenerbahce University"]}'
+
+// 11- To register faculties and departments with stable codes and to map the free-text names of existing records to them
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"RegisterFaculty","Args":["Fenerbahce University", "FEA", "Faculty of Engineering and Architecture", "[]"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"RegisterDepartment","Args":["Fenerbahce University", "CENG", "FEA", "Department of Computer Engineering", "[\"Computer Engineering\"]", "[\"COMP\"]"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_Departments", "Fenerbahce University"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"MigrateDepartmentCodes","Args":["Fenerbahce University"]}'
+
+// 12- To define a program's learning outcomes, map courses to them, and to query a student's competency profile
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"DefineLearningOutcome","Args":["Fenerbahce University", "CENG-BSc", "PO1", "Apply knowledge of mathematics, science and engineering"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"MapCourseToOutcome","Args":["Fenerbahce University", "CENG-BSc", "PO1", "MATH1001", "5"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetStudentCompetencyProfile", "Fenerbahce University", "190908809", "CENG-BSc"]}'
+
+// 13- Dates are stored as ISO-8601 (YYYY-MM-DD); DD.MM.YYYY input is accepted and converted. To convert the dates of existing records
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"MigrateDatesToISO","Args":["Fenerbahce University"]}'
+
+// 14- The transcript carries the GPA of each semester and the CGPA; to weight them by course credits ("credit") or by ECTS ("ects")
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"SetGPAWeighting","Args":["Fenerbahce University", "ects"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_Config", "Fenerbahce University"]}'
+
+// 15- To register a version of a grading scale, effective from a date, and to validate the HEI's grades against it (the AA-FF scale is used until then)
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"RegisterGradingScale","Args":["Fenerbahce University", "LETTER-PM", "A-F with plus/minus", "2024-09-01", "false", "[{\"code\":\"A\",\"coefficient\":4,\"passing\":true,\"counts_in_gpa\":true,\"min_score\":93,\"max_score\":100},{\"code\":\"A-\",\"coefficient\":3.7,\"passing\":true,\"counts_in_gpa\":true,\"min_score\":90,\"max_score\":92}]"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"SetGradingScale","Args":["Fenerbahce University", "LETTER-PM"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_GradingScaleInForce", "Fenerbahce University"]}'
+
+// 16- The point of a taken course is checked against grade coefficient × credits (or ECTS); to reject instead of flagging disagreeing points, and to list the flagged records
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"SetPointCheck","Args":["Fenerbahce University", "reject"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_FlaggedRecords", "Fenerbahce University"]}'
+
+// 17- To set the honor rules (honor GPA, high honor GPA, minimum course load, no failed courses) and to list a department's honor students for a term
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"SetHonorRules","Args":["Fenerbahce University", "3.00", "3.50", "30", "true"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetHonorStudents", "Fenerbahce University", "CENG", "2023-2024 Fall"]}'
+
+// 18- To query a student's class rank and percentile by CGPA within the department and entry cohort
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetStudentCohortRank", "Fenerbahce University", "190908809"]}'
+
+// 19- To set the standing rules (probation below a CGPA after a semester), evaluated whenever a grade is inserted, and to list a department's students on probation
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"SetStandingRules","Args":["Fenerbahce University", "[{\"after_semester\":2,\"min_cgpa\":1.80},{\"after_semester\":4,\"min_cgpa\":2.00}]"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"EvaluateStudentStanding","Args":["Fenerbahce University", "190908809"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_Standing", "Fenerbahce University", "190908809"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetProbationStudents", "Fenerbahce University", "CENG"]}'
+
+// 20- Class and semester are derived from the registered terms and the earned credits (or ECTS); stored values that disagree are flagged
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"RegisterStudentTerm","Args":["Fenerbahce University", "190908809", "2022-2023 Fall", "Registered"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"SetClassThresholds","Args":["Fenerbahce University", "[60, 120, 180]"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetStudentProgress", "Fenerbahce University", "190908809"]}'
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"CheckStudentProgress","Args":["Fenerbahce University", "190908809"]}'
+
+// 21- To query a student's transcript as it was at a moment (RFC3339), or at the end of a day (YYYY-MM-DD), rebuilt from the history of the records
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetStudentTranscriptAsOf", "Fenerbahce University", "190908809", "2026-06-30"]}'
+
+// 22- To compare two states of a student's transcript, each given by a timestamp or a transaction ID (an empty second state is the current one)
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetStudentTranscriptDiff", "Fenerbahce University", "190908809", "2026-06-30", ""]}'
+
+// 23- The transcript reports taken courses without a course info, course codes with several course infos and a missing student info; to fail on them instead
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetStudentTranscriptStrict", "Fenerbahce University", "190908809"]}'
+
+// 24- Every Get_HEI_* and Get_Student_* list query has a _Paginated variant taking a page size and a bookmark (empty for the first page); pass the returned bookmark to get the next page
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_HEI_TakenCourses_Paginated", "Fenerbahce University", "100", ""]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["Get_Student_TakenCourses_Paginated", "Fenerbahce University", "190908809", "20", "<bookmark of the previous page>"]}'
+
+// 25- Lookups by relation read relation-specific composite keys; to write them for the records an HEI stored before they were introduced
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"MigrateRelationKeys","Args":["Fenerbahce University"]}'
+
+// 26- To search an HEI's students or taken courses by faculty, department, program type, registration date range, course code, grades and term, sorted and paginated
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["SearchStudents", "Fenerbahce University", "{\"department\":\"CENG\",\"program_type\":\"Undergraduate\",\"registered_from\":\"2020-01-01\",\"registered_to\":\"2021-12-31\"}", "student_surname", "false", "50", ""]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["SearchTakenCourses", "Fenerbahce University", "{\"course_code\":\"STAT2003\",\"grades\":[\"DC\",\"DD\",\"FD\",\"FF\"]}", "grade", "false", "50", ""]}'
+
+// 27- To query the grade distribution of a course in a term (counts per grade, mean grade coefficient, pass rate); only courses taken in an offering have a term; cohorts below the minimum size (5 by default) are suppressed
+// peer chaincode invoke -C mychannel -n mySmartContract -c '{"function":"SetMinCohortSize","Args":["Fenerbahce University", "10"]}'
+// peer chaincode query -C mychannel -n mySmartContract -c '{"Args":["GetCourseStatistics", "Fenerbahce University", "COMP2004", "2023-2024 Fall"]}'
+
 // ------------------------------------------------------------------------------------------------------
 // *
 // * Data structures employed during transcript operations
@@ -60,11 +150,13 @@ type StudentInfo struct {
 	StudentSurname   string `json:"student_surname"`
 	StudentName      string `json:"student_name"`
 	NationalID       string `json:"national_id"`
-	RegistrationDate string `json:"registration_date"`
+	RegistrationDate string `json:"registration_date"` // ISO-8601 date, YYYY-MM-DD
 	RegistrationType string `json:"registration_type"`
 	ProgramType      string `json:"program_type"`
 	Class            int    `json:"class"`
 	StudentSemester  int    `json:"student_semester"`
+	FacultyCode      string `json:"faculty_code,omitempty" metadata:",optional"`    // Code of the registered Faculty entity
+	DepartmentCode   string `json:"department_code,omitempty" metadata:",optional"` // Code of the registered Department entity
 	HashValue        string `json:"hash_value"`
 }
 
@@ -73,27 +165,35 @@ type TakenCourse struct {
 	StudentID     int     `json:"student_id"`
 	CourseCode    string  `json:"course_code"`
 	Grade         string  `json:"grade"`
-	Point         float32 `json:"point"`
+	Point         Decimal `json:"point"`
 	TakenSemester int     `json:"taken_semester"`
+	Term          string  `json:"term,omitempty" metadata:",optional"`    // Term of the course offering the course was taken in
+	Section       string  `json:"section,omitempty" metadata:",optional"` // Section of the course offering the course was taken in
 	HashValue     string  `json:"hash_value"`
 }
 
 // CourseInfo creates a data structure that corresponds to a relation in the relational data model of relational database management system (RDBMS)
 type CourseInfo struct {
-	CourseCode string `json:"course_code"`
-	CourseName string `json:"course_name"`
-	CourseType string `json:"course_type"`
-	ECTS       int    `json:"ects"`
-	Credit     int    `json:"credit"`
-	HashValue  string `json:"hash_value"`
+	CourseCode     string `json:"course_code"`
+	CourseName     string `json:"course_name"`
+	CourseType     string `json:"course_type"`
+	ECTS           int    `json:"ects"`
+	Credit         int    `json:"credit"`
+	DepartmentCode string `json:"department_code,omitempty" metadata:",optional"` // Code of the registered Department entity offering the course
+	HashValue      string `json:"hash_value"`
 }
 
 // For each created StudentInfo, TakenCourse, and CourseInfo record, a MetaInfo record is created
 type MetaInfo struct {
-	Owner     string `json:"owner"`      // HEI Name
-	StudentID string `json:"student_id"` // Student ID
-	Relation  string `json:"relation"`   // Corresponds to a relation name in RDMS
-	HashValue string `json:"hash_value"` // Calculated hash value of except HashCode field
+	Owner        string   `json:"owner"`                                        // HEI Name
+	StudentID    string   `json:"student_id"`                                   // Student ID
+	Relation     string   `json:"relation"`                                     // Corresponds to a relation name in RDMS
+	HashValue    string   `json:"hash_value"`                                   // Calculated hash value of except HashCode field
+	Version      int      `json:"version,omitempty" metadata:",optional"`       // Version number of an amendable record, 0 is read as the first version
+	SupersededBy string   `json:"superseded_by,omitempty" metadata:",optional"` // Hash value of the version that replaced this one, empty for the current version
+	Reason       string   `json:"reason,omitempty" metadata:",optional"`        // Why this version was written
+	RecordedAt   string   `json:"recorded_at,omitempty" metadata:",optional"`   // Transaction timestamp of the version, RFC3339
+	Flags        []string `json:"flags,omitempty" metadata:",optional"`         // Problems found in the record when it or a record it depends on was stored, e.g. an unexpected point
 }
 
 // Taken courses (TakenCourse) and courses info (CourseInfo) are combined to construct a transcript
@@ -104,14 +204,25 @@ type CombinedCourseRecords struct {
 	ECTS          int     `json:"ects"`
 	Credit        int     `json:"credit"`
 	Grade         string  `json:"grade"`
-	Point         float32 `json:"point"`
+	Point         Decimal `json:"point"`
 	TakenSemester int     `json:"taken_semester"`
+	Term          string  `json:"term,omitempty" metadata:",optional"`
+	Section       string  `json:"section,omitempty" metadata:",optional"`
+	Instructor    string  `json:"instructor,omitempty" metadata:",optional"`
+	Language      string  `json:"language,omitempty" metadata:",optional"`
+	DeliveryMode  string  `json:"delivery_mode,omitempty" metadata:",optional"`
 }
 
 // This is the ultimate data structure that consists of StudentInfo, CourseInfo, and TakenCourses to respond to a student’s queried transcript.
 type StudentTranscript struct {
-	InfoStudent StudentInfo             `json:"student_informations"`
-	Courses     []CombinedCourseRecords `json:"taken_courses"`
+	InfoStudent  StudentInfo             `json:"student_informations"`
+	Program      *ProgramEnrollment      `json:"program,omitempty" metadata:",optional"` // Set only for a program-specific transcript
+	Courses      []CombinedCourseRecords `json:"taken_courses"`
+	Weighting    string                  `json:"weighting"` // Course weight of the averages: credit or ects, as configured for the HEI
+	Terms        []TermBlock             `json:"terms"`     // The courses grouped by semester, in semester order, with term and cumulative totals
+	Totals       GPASummary              `json:"totals"`    // Totals of all semesters, counting a repeated course with its latest attempt only
+	CGPA         float64                 `json:"cgpa"`
+	Completeness CompletenessReport      `json:"completeness"` // Records that could not be joined into the transcript
 }
 
 //------------------------------------------------------------------------------------------------------
@@ -122,7 +233,7 @@ type StudentTranscript struct {
 //
 
 func (Transcript *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
-	var compositeKey, generatedHashValue string
+	var generatedHashValue string
 
 	// 1- Create studentinfos and add them to the ledger
 	Students := []StudentInfo{{Faculty: "Faculty of Engineering and Architecture",
@@ -131,7 +242,7 @@ func (Transcript *SmartContract) InitLedger(ctx contractapi.TransactionContextIn
 		StudentSurname:   "Selvi",
 		StudentName:      "Osman",
 		NationalID:       "44262495576",
-		RegistrationDate: "02.09.2022",
+		RegistrationDate: "2022-09-02",
 		RegistrationType: "Major / OSYM",
 		ProgramType:      "Undergraduate",
 		Class:            1,
@@ -160,31 +271,21 @@ func (Transcript *SmartContract) InitLedger(ctx contractapi.TransactionContextIn
 			return fmt.Errorf("failed to put student info to world state. %v", err)
 		}
 
-		compositeKey, err = ctx.GetStub().CreateCompositeKey("heiID", []string{MetaStudents[index].Owner, MetaStudents[index].StudentID, MetaStudents[index].HashValue})
-		if err != nil {
-			return fmt.Errorf("failed to create composite key: %v", err)
-		}
-
-		infoJSON, err = json.Marshal(MetaStudents[index])
-		if err != nil {
-			return fmt.Errorf("failed to convert struct to json object: %v", err)
-		}
-
-		err = ctx.GetStub().PutState(compositeKey, infoJSON)
+		err = putMetaInfo(ctx, MetaStudents[index], infoJSON)
 		if err != nil {
-			return fmt.Errorf("failed to put meta student info to world state. %v", err)
+			return err
 		}
 	}
 
 	// 2- Create taken courses and add them to the ledger
-	TakenCourses := []TakenCourse{{StudentID: 190908809, CourseCode: "COMP1001", Grade: "AA", Point: 20, TakenSemester: 1, HashValue: ""},
-		{StudentID: 190908809, CourseCode: "COMP1003", Grade: "BA", Point: 21, TakenSemester: 1, HashValue: ""},
-		{StudentID: 190908809, CourseCode: "ENG103", Grade: "BB", Point: 6, TakenSemester: 1, HashValue: ""},
-		{StudentID: 190908809, CourseCode: "MATH1001", Grade: "CB", Point: 18.9, TakenSemester: 1, HashValue: ""},
-		{StudentID: 190908809, CourseCode: "PHYS1001", Grade: "CC", Point: 8, TakenSemester: 1, HashValue: ""},
-		{StudentID: 190908809, CourseCode: "PHYS1011", Grade: "CC", Point: 4, TakenSemester: 1, HashValue: ""},
-		{StudentID: 190908809, CourseCode: "TURK103", Grade: "BB", Point: 6, TakenSemester: 1, HashValue: ""},
-		{StudentID: 190908809, CourseCode: "UNI103", Grade: "AA", Point: 8, TakenSemester: 1, HashValue: ""},
+	TakenCourses := []TakenCourse{{StudentID: 190908809, CourseCode: "COMP1001", Grade: "AA", Point: "20", TakenSemester: 1, HashValue: ""},
+		{StudentID: 190908809, CourseCode: "COMP1003", Grade: "BA", Point: "21", TakenSemester: 1, HashValue: ""},
+		{StudentID: 190908809, CourseCode: "ENG103", Grade: "BB", Point: "6", TakenSemester: 1, HashValue: ""},
+		{StudentID: 190908809, CourseCode: "MATH1001", Grade: "CB", Point: "18.9", TakenSemester: 1, HashValue: ""},
+		{StudentID: 190908809, CourseCode: "PHYS1001", Grade: "CC", Point: "8", TakenSemester: 1, HashValue: ""},
+		{StudentID: 190908809, CourseCode: "PHYS1011", Grade: "CC", Point: "4", TakenSemester: 1, HashValue: ""},
+		{StudentID: 190908809, CourseCode: "TURK103", Grade: "BB", Point: "6", TakenSemester: 1, HashValue: ""},
+		{StudentID: 190908809, CourseCode: "UNI103", Grade: "AA", Point: "8", TakenSemester: 1, HashValue: ""},
 	}
 
 	MetaTakenCourses := []MetaInfo{{Owner: "Fenerbahce University", StudentID: "190908809", Relation: "TakenCourse", HashValue: "NULL"},
@@ -212,19 +313,9 @@ func (Transcript *SmartContract) InitLedger(ctx contractapi.TransactionContextIn
 			return fmt.Errorf("failed to put course record to world state. %v", err)
 		}
 
-		compositeKey, err = ctx.GetStub().CreateCompositeKey("heiID", []string{MetaTakenCourses[index2].Owner, MetaTakenCourses[index2].StudentID, MetaTakenCourses[index2].HashValue})
+		err = putMetaInfo(ctx, MetaTakenCourses[index2], infoJSON)
 		if err != nil {
-			return fmt.Errorf("failed to create composite key: %v", err)
-		}
-
-		infoJSON, err = json.Marshal(MetaTakenCourses[index2])
-		if err != nil {
-			return fmt.Errorf("failed to convert struct to json object: %v", err)
-		}
-
-		err = ctx.GetStub().PutState(compositeKey, infoJSON)
-		if err != nil {
-			return fmt.Errorf("failed to put meta student info to world state. %v", err)
+			return err
 		}
 
 	}
@@ -265,21 +356,17 @@ func (Transcript *SmartContract) InitLedger(ctx contractapi.TransactionContextIn
 			return fmt.Errorf("failed to put course info record to world state. %v", err)
 		}
 
-		compositeKey, err = ctx.GetStub().CreateCompositeKey("heiID", []string{MetaCourseInfoS[index3].Owner, MetaCourseInfoS[index3].StudentID, MetaCourseInfoS[index3].HashValue})
-		if err != nil {
-			return fmt.Errorf("failed to create composite key: %v", err)
-		}
-
-		infoJSON, err = json.Marshal(MetaCourseInfoS[index3])
+		err = putMetaInfo(ctx, MetaCourseInfoS[index3], infoJSON)
 		if err != nil {
-			return fmt.Errorf("failed to convert struct to json object: %v", err)
+			return err
 		}
 
-		err = ctx.GetStub().PutState(compositeKey, infoJSON)
-		if err != nil {
-			return fmt.Errorf("failed to put meta course info to world state. %v", err)
-		}
+	}
 
+	// 4- The points of the sample records are weighted by ECTS
+	err := putHEIConfig(ctx, &HEIConfig{Owner: "Fenerbahce University", Weighting: weightingECTS})
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -294,15 +381,20 @@ func StructToMD5(incomingStruct interface{}) (generatedHashValue string) {
 func StructToString(incomingStruct interface{}) (result string) {
 	values := reflect.ValueOf(incomingStruct)
 	numberOfFields := values.NumField()
-	mySlice := make([]string, numberOfFields)
+	mySlice := make([]string, 0, numberOfFields)
 
 	for i := 0; i < numberOfFields; i++ {
 		fieldType := values.Type().Field(i).Name
-		fmt.Println(fieldType)
+		// Optional fields are left out while empty, so records that do not use them keep the hash value they had before the field was added
+		if strings.Contains(values.Type().Field(i).Tag.Get("json"), ",omitempty") && values.Field(i).IsZero() {
+			continue
+		}
 		if fieldType != "HashCode" { // Convert everthing to a string with comma expect from HashCode field
 			value := reflect.ValueOf(values.Field(i)).Interface()
 			stringData := fmt.Sprintf("%v", value)
-			mySlice[i] = stringData
+			mySlice = append(mySlice, stringData)
+		} else {
+			mySlice = append(mySlice, "")
 		}
 	}
 
@@ -318,21 +410,53 @@ func StringToMD5(value string) string {
 }
 
 func (Transcript *SmartContract) IsRecordExists(ctx contractapi.TransactionContextInterface, Owner string, StudentID string, HashCode string) (bool, error) {
-	queryString := fmt.Sprintf(`{"selector":{"owner":"%s","student_id":"%s", "hash_value":"%s"}}`, Owner, StudentID, HashCode)
+	IsExist := false
 
-	resultsIterator, err := ctx.GetStub().GetQueryResult(queryString)
+	err := iterateLookup(ctx, recordLookup(Owner, StudentID, HashCode), func(value []byte) error {
+		IsExist = true
+		return nil
+	})
+	if err != nil {
+		return true, err
+	}
+
+	return IsExist, nil
+}
 
+// putRecordWithMeta stores a record under its hash value and indexes it with a MetaInfo record under the "heiID" composite key and its relation key
+func putRecordWithMeta(ctx contractapi.TransactionContextInterface, owner string, studentID string, relation string, hashValue string, record interface{}) error {
+	jsonRecord, err := json.Marshal(record)
 	if err != nil {
-		return true, fmt.Errorf("failed to read from worldstate db : %v", err)
+		return fmt.Errorf("failed to convert struct to json object: %v", err)
 	}
 
-	if resultsIterator.HasNext() {
-		return true, nil
+	err = ctx.GetStub().PutState(hashValue, jsonRecord)
+	if err != nil {
+		return fmt.Errorf("failed to put %s record to world state. %v", relation, err)
 	}
 
-	defer resultsIterator.Close()
+	meta := MetaInfo{Owner: owner, StudentID: studentID, Relation: relation, HashValue: hashValue}
 
-	return false, nil
+	return putMetaInfo(ctx, meta, jsonRecord)
+}
+
+// getRecordByHashValue reads the record stored under the given hash value into the given struct pointer
+func getRecordByHashValue(ctx contractapi.TransactionContextInterface, hashValue string, record interface{}) error {
+	jsonData, err := ctx.GetStub().GetState(hashValue)
+	if err != nil {
+		return fmt.Errorf("failed to read from worldstate db : %v", err)
+	}
+
+	if jsonData == nil {
+		return fmt.Errorf("there is not a record with the given hash value: %v", hashValue)
+	}
+
+	err = json.Unmarshal(jsonData, record)
+	if err != nil {
+		return fmt.Errorf("failed to fetch json data to struct : %v", err)
+	}
+
+	return nil
 }
 
 //------------------------------------------------------------------------------------------------------
@@ -345,7 +469,7 @@ func (Transcript *SmartContract) InsertNewRecordStudentInfo(ctx contractapi.Tran
 	studentId int, surname string, name string, nationalid string, registrationdate string, registrationtype string, programtype string, class int, semester int) (bool, error) {
 
 	var err error
-	var compositeKey, generatedHashValue string
+	var generatedHashValue string
 	var IsExist bool
 	var student StudentInfo
 	var meta MetaInfo
@@ -356,12 +480,32 @@ func (Transcript *SmartContract) InsertNewRecordStudentInfo(ctx contractapi.Tran
 	student.StudentSurname = surname
 	student.StudentName = name
 	student.NationalID = nationalid
-	student.RegistrationDate = registrationdate
+	student.RegistrationDate, err = normalizeDate(registrationdate)
+	if err != nil {
+		return false, err
+	}
 	student.RegistrationType = registrationtype
 	student.ProgramType = programtype
 	student.Class = class
 	student.StudentSemester = semester
 
+	organization, err := loadOrganization(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	student.FacultyCode, student.DepartmentCode, err = organization.resolveStudentUnits(faculty, department)
+	if err != nil {
+		return false, err
+	}
+
+	progress, err := deriveStudentProgress(ctx, owner, &student)
+	if err != nil {
+		return false, err
+	}
+
+	meta.Flags = progress.Flags
+
 	generatedHashValue = StructToMD5(student)
 	student.HashValue = generatedHashValue
 
@@ -389,39 +533,65 @@ func (Transcript *SmartContract) InsertNewRecordStudentInfo(ctx contractapi.Tran
 	meta.Relation = "StudentInfo"
 	meta.HashValue = generatedHashValue
 
-	compositeKey, err = ctx.GetStub().CreateCompositeKey("heiID", []string{meta.Owner, meta.StudentID, meta.HashValue})
+	err = putMetaInfo(ctx, meta, jsonStudent)
 	if err != nil {
-		return false, fmt.Errorf("failed to create composite key: %v", err)
+		return false, err
 	}
 
-	jsonMeta, err := json.Marshal(meta)
+	err = Transcript.evaluateStanding(ctx, owner, meta.StudentID, pendingRecords{infoStudent: &student})
 	if err != nil {
-		return false, fmt.Errorf("failed to convert struct to json object: %v", err)
-	}
-
-	err = ctx.GetStub().PutState(compositeKey, jsonMeta)
-	if err != nil {
-		return false, fmt.Errorf("failed to put meta student info to world state. %v", err)
+		return false, err
 	}
 
 	return true, nil
 }
 
 func (Transcript *SmartContract) InsertNewRecordTakenCourse(ctx contractapi.TransactionContextInterface, owner string, studentId int,
-	courseCode string, grade string, point float32, takenSemester int) (bool, error) {
+	courseCode string, grade string, point string, takenSemester int) (bool, error) {
 
-	var err error
-	var compositeKey, generatedHashValue string
-	var IsExist bool
 	var course TakenCourse
-	var meta MetaInfo
+	var err error
 
 	course.StudentID = studentId
 	course.CourseCode = courseCode
 	course.Grade = grade
-	course.Point = point
+	course.Point, err = parseDecimal(point)
+	if err != nil {
+		return false, err
+	}
 	course.TakenSemester = takenSemester
 
+	return Transcript.insertTakenCourse(ctx, owner, course)
+}
+
+// insertTakenCourse stores a TakenCourse record, optionally referencing a course offering, together with its MetaInfo record
+func (Transcript *SmartContract) insertTakenCourse(ctx contractapi.TransactionContextInterface, owner string, course TakenCourse) (bool, error) {
+	var err error
+	var generatedHashValue string
+	var IsExist bool
+	var meta MetaInfo
+
+	studentId := course.StudentID
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	scales, err := loadGradingScales(ctx, config)
+	if err != nil {
+		return false, err
+	}
+
+	if scales.inForce().grade(course.Grade) == nil {
+		return false, fmt.Errorf("the grade %q is not defined by the grading scale %s in force for %s", course.Grade, scales.inForce().ScaleID, owner)
+	}
+
+	meta.Flags, err = checkTakenCoursePoint(ctx, config, scales, course)
+	if err != nil {
+		return false, err
+	}
+
 	generatedHashValue = StructToMD5(course)
 	course.HashValue = generatedHashValue
 
@@ -449,19 +619,14 @@ func (Transcript *SmartContract) InsertNewRecordTakenCourse(ctx contractapi.Tran
 	meta.Relation = "TakenCourse"
 	meta.HashValue = generatedHashValue
 
-	compositeKey, err = ctx.GetStub().CreateCompositeKey("heiID", []string{meta.Owner, meta.StudentID, meta.HashValue})
-	if err != nil {
-		return false, fmt.Errorf("failed to create composite key: %v", err)
-	}
-
-	jsonMeta, err := json.Marshal(meta)
+	err = putMetaInfo(ctx, meta, jsonCourse)
 	if err != nil {
-		return false, fmt.Errorf("failed to convert struct to json object: %v", err)
+		return false, err
 	}
 
-	err = ctx.GetStub().PutState(compositeKey, jsonMeta)
+	err = Transcript.evaluateStandingWith(ctx, owner, config, scales, strconv.Itoa(studentId), pendingRecords{courseTaken: &course})
 	if err != nil {
-		return false, fmt.Errorf("failed to put meta student info to world state. %v", err)
+		return false, err
 	}
 
 	return true, nil
@@ -471,7 +636,7 @@ func (Transcript *SmartContract) InsertNewRecordCourseInfo(ctx contractapi.Trans
 	courseCode string, courseName string, courseType string, ects int, credit int) (bool, error) {
 
 	var err error
-	var compositeKey, generatedHashValue string
+	var generatedHashValue string
 	var IsExist bool
 	var InfoCourse CourseInfo
 	var meta MetaInfo
@@ -482,6 +647,28 @@ func (Transcript *SmartContract) InsertNewRecordCourseInfo(ctx contractapi.Trans
 	InfoCourse.Credit = credit
 	InfoCourse.ECTS = ects
 
+	organization, err := loadOrganization(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	InfoCourse.DepartmentCode = organization.resolveCourseDepartment(courseCode)
+
+	config, err := loadHEIConfig(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	scales, err := loadGradingScales(ctx, config)
+	if err != nil {
+		return false, err
+	}
+
+	err = checkStoredTakenCoursePoints(ctx, config, scales, strconv.Itoa(studentnumber), &InfoCourse)
+	if err != nil {
+		return false, err
+	}
+
 	generatedHashValue = StructToMD5(InfoCourse)
 	InfoCourse.HashValue = generatedHashValue
 
@@ -509,20 +696,16 @@ func (Transcript *SmartContract) InsertNewRecordCourseInfo(ctx contractapi.Trans
 	meta.Relation = "CourseInfo"
 	meta.HashValue = generatedHashValue
 
-	compositeKey, err = ctx.GetStub().CreateCompositeKey("heiID", []string{meta.Owner, meta.StudentID, meta.HashValue})
+	err = putMetaInfo(ctx, meta, jsonCourse)
 	if err != nil {
-		return false, fmt.Errorf("failed to create composite key: %v", err)
+		return false, err
 	}
 
-	jsonMeta, err := json.Marshal(meta)
+	err = Transcript.evaluateStandingWith(ctx, owner, config, scales, meta.StudentID, pendingRecords{infoCourse: &InfoCourse})
 	if err != nil {
-		return false, fmt.Errorf("failed to convert struct to json object: %v", err)
+		return false, err
 	}
 
-	err = ctx.GetStub().PutState(compositeKey, jsonMeta)
-	if err != nil {
-		return false, fmt.Errorf("failed to put meta student info to world state. %v", err)
-	}
 	return true, nil
 }
 
@@ -533,55 +716,40 @@ func (Transcript *SmartContract) InsertNewRecordCourseInfo(ctx contractapi.Trans
 //------------------------------------------------------------------------------------------------------
 
 func (Transcript *SmartContract) Get_Student_StudentInfo(ctx contractapi.TransactionContextInterface, hei string, studentID string) (*StudentInfo, error) {
-	var recordStudentInfo *StudentInfo
-	var err error
-	var hashValueofStudentInfo []string
-
-	hashValueofStudentInfo, err = Transcript.Get_Student_StudentInfo_HashValues(ctx, hei, studentID)
+	entries, err := getStudentRecordEntries(ctx, hei, "StudentInfo", studentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	for index := 0; index < len(hashValueofStudentInfo); index++ {
-		infoStudent, err := Transcript.Get_StudentInfo_ByHashValue(ctx, hashValueofStudentInfo[index])
-		if err != nil {
-			return nil, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
-		}
+	current := currentRecordEntry(entries)
+	if current == nil {
+		return nil, fmt.Errorf("failed to read from worldstate db : no record were found relevant to the given arguments on worldstate db")
+	}
 
-		recordStudentInfo = infoStudent
+	var infoStudent StudentInfo
+	err = decodeRecord(ctx, current, &infoStudent)
+	if err != nil {
+		return nil, fmt.Errorf("error during fetch student info record by hash value: %v", err)
 	}
 
-	return recordStudentInfo, nil
+	return &infoStudent, nil
 }
 
 func (Transcript *SmartContract) Get_Student_StudentInfo_HashValues(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]string, error) {
-	var iterator shim.StateQueryIteratorInterface
-	var err error
-
 	var hashValues []string
 
-	queryString := fmt.Sprintf(`{"selector":{"owner":"%s", "relation":"StudentInfo", "student_id":"%s"}}`, hei, studentID)
-
-	iterator, err = ctx.GetStub().GetQueryResult(queryString)
+	records, err := getStudentMetaInfos(ctx, hei, "StudentInfo", studentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	if !iterator.HasNext() {
+	if len(records) == 0 {
 		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
 	}
 
-	defer iterator.Close()
-
-	for iterator.HasNext() {
-		var record MetaInfo
-		queryRow, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
-		}
-		err = json.Unmarshal(queryRow.Value, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
 		}
 
 		hashValues = append(hashValues, record.HashValue)
@@ -617,54 +785,48 @@ func (Transcript *SmartContract) Get_StudentInfo_ByHashValue(ctx contractapi.Tra
 
 func (Transcript *SmartContract) Get_Student_CourseInfos(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*CourseInfo, error) {
 	var recordsCourseInfos []*CourseInfo
-	var err error
-	var hashValuesofCourseInfos []string
 
-	hashValuesofCourseInfos, err = Transcript.Get_Student_CourseInfos_HashValues(ctx, hei, studentID)
+	entries, err := getStudentRecordEntries(ctx, hei, "CourseInfo", studentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	for index := 0; index < len(hashValuesofCourseInfos); index++ {
-		course, err := Transcript.Get_CourseInfo_ByHashValue(ctx, hashValuesofCourseInfos[index])
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("failed to read from worldstate db : no record were found relevant to the given arguments on worldstate db")
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+
+		var course CourseInfo
+		err = decodeRecord(ctx, entry, &course)
 		if err != nil {
 			return nil, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
 		}
 
-		recordsCourseInfos = append(recordsCourseInfos, course)
+		recordsCourseInfos = append(recordsCourseInfos, &course)
 	}
 
 	return recordsCourseInfos, nil
 }
 
 func (Transcript *SmartContract) Get_Student_CourseInfos_HashValues(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]string, error) {
-	var iterator shim.StateQueryIteratorInterface
-	var err error
-
 	var hashValues []string
 
-	queryString := fmt.Sprintf(`{"selector":{"owner":"%s", "relation":"CourseInfo", "student_id":"%s"}}`, hei, studentID)
-
-	iterator, err = ctx.GetStub().GetQueryResult(queryString)
+	records, err := getStudentMetaInfos(ctx, hei, "CourseInfo", studentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	if !iterator.HasNext() {
+	if len(records) == 0 {
 		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
 	}
 
-	defer iterator.Close()
-
-	for iterator.HasNext() {
-		var record MetaInfo
-		queryRow, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
-		}
-		err = json.Unmarshal(queryRow.Value, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
 		}
 
 		hashValues = append(hashValues, record.HashValue)
@@ -700,56 +862,48 @@ func (Transcript *SmartContract) Get_CourseInfo_ByHashValue(ctx contractapi.Tran
 
 func (Transcript *SmartContract) Get_Student_TakenCourses(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*TakenCourse, error) {
 	var recordsTakenCourses []*TakenCourse
-	var err error
-	var hashValuesofTakenCourses []string
 
-	hashValuesofTakenCourses, err = Transcript.Get_Student_TakenCourses_HashValues(ctx, hei, studentID)
+	entries, err := getStudentRecordEntries(ctx, hei, "TakenCourse", studentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	for index := 0; index < len(hashValuesofTakenCourses); index++ {
-		course, err := Transcript.Get_TakenCourse_ByHashValue(ctx, hashValuesofTakenCourses[index])
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("failed to read from worldstate db : no record were found relevant to the given arguments on worldstate db")
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+
+		var course TakenCourse
+		err = decodeRecord(ctx, entry, &course)
 		if err != nil {
 			return nil, fmt.Errorf("error during fetch taken course record by hash value: %v", err)
 		}
 
-		// Get course infoyu da alıp birleştirmeli burada
-
-		recordsTakenCourses = append(recordsTakenCourses, course)
+		recordsTakenCourses = append(recordsTakenCourses, &course)
 	}
 
 	return recordsTakenCourses, nil
 }
 
 func (Transcript *SmartContract) Get_Student_TakenCourses_HashValues(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]string, error) {
-	var iterator shim.StateQueryIteratorInterface
-	var err error
-
 	var hashValues []string
 
-	queryString := fmt.Sprintf(`{"selector":{"owner":"%s", "relation":"TakenCourse", "student_id":"%s"}}`, hei, studentID)
-
-	iterator, err = ctx.GetStub().GetQueryResult(queryString)
+	records, err := getStudentMetaInfos(ctx, hei, "TakenCourse", studentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	if !iterator.HasNext() {
+	if len(records) == 0 {
 		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
 	}
 
-	defer iterator.Close()
-
-	for iterator.HasNext() {
-		var record MetaInfo
-		queryRow, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
-		}
-		err = json.Unmarshal(queryRow.Value, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
 		}
 
 		hashValues = append(hashValues, record.HashValue)
@@ -784,57 +938,42 @@ func (Transcript *SmartContract) Get_TakenCourse_ByHashValue(ctx contractapi.Tra
 //------------------------------------------------------------------------------------------------------
 
 func (Transcript *SmartContract) Get_HEI_TakenCourses(ctx contractapi.TransactionContextInterface, hei string) ([]*TakenCourse, error) {
-	var records []*MetaInfo
-	var recordsStudentInfo []*TakenCourse
-	var err error
+	var records []*TakenCourse
 
-	records, err = Transcript.Get_HEI_MetaInfos_TakenCourses(ctx, hei)
+	entries, err := getHEIRecordEntries(ctx, hei, "TakenCourse")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	for index := 0; index < len(records); index++ {
-		recordStudentInfo, err := Transcript.Get_TakenCourse_ByHashValue(ctx, records[index].HashValue)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("failed to read from worldstate db : no record were found relevant to the given arguments on worldstate db")
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+
+		var record TakenCourse
+		err = decodeRecord(ctx, entry, &record)
 		if err != nil {
 			return nil, fmt.Errorf("%v", err)
 		}
-		recordsStudentInfo = append(recordsStudentInfo, recordStudentInfo)
+		records = append(records, &record)
 	}
-	return recordsStudentInfo, nil
+	return records, nil
 }
 
 func (Transcript *SmartContract) Get_HEI_MetaInfos_TakenCourses(ctx contractapi.TransactionContextInterface, hei string) ([]*MetaInfo, error) {
-	var iterator shim.StateQueryIteratorInterface
-	var records []*MetaInfo
-	var err error
-
-	var queryString = fmt.Sprintf(`{"selector":{"owner":"%s", "relation":"TakenCourse"}}`, hei)
-	iterator, err = ctx.GetStub().GetQueryResult(queryString)
-
+	records, err := getHEIMetaInfos(ctx, hei, "TakenCourse")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	if !iterator.HasNext() {
+	if len(records) == 0 {
 		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
 	}
 
-	defer iterator.Close()
-
-	for iterator.HasNext() {
-		queryRow, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
-		}
-		var record MetaInfo
-		err = json.Unmarshal(queryRow.Value, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
-		}
-
-		records = append(records, &record)
-	}
-
 	return records, nil
 }
 
@@ -845,57 +984,42 @@ func (Transcript *SmartContract) Get_HEI_MetaInfos_TakenCourses(ctx contractapi.
 //------------------------------------------------------------------------------------------------------
 
 func (Transcript *SmartContract) Get_HEI_StudentInfos(ctx contractapi.TransactionContextInterface, hei string) ([]*StudentInfo, error) {
-	var records []*MetaInfo
-	var recordsStudentInfo []*StudentInfo
-	var err error
+	var records []*StudentInfo
 
-	records, err = Transcript.Get_HEI_MetaInfos_StudentInfos(ctx, hei)
+	entries, err := getHEIRecordEntries(ctx, hei, "StudentInfo")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	for index := 0; index < len(records); index++ {
-		recordStudentInfo, err := Transcript.Get_StudentInfo_ByHashValue(ctx, records[index].HashValue)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("failed to read from worldstate db : no record were found relevant to the given arguments on worldstate db")
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+
+		var record StudentInfo
+		err = decodeRecord(ctx, entry, &record)
 		if err != nil {
 			return nil, fmt.Errorf("%v", err)
 		}
-		recordsStudentInfo = append(recordsStudentInfo, recordStudentInfo)
+		records = append(records, &record)
 	}
-	return recordsStudentInfo, nil
+	return records, nil
 }
 
 func (Transcript *SmartContract) Get_HEI_MetaInfos_StudentInfos(ctx contractapi.TransactionContextInterface, hei string) ([]*MetaInfo, error) {
-	var iterator shim.StateQueryIteratorInterface
-	var records []*MetaInfo
-	var err error
-
-	var queryString = fmt.Sprintf(`{"selector":{"owner":"%s", "relation":"StudentInfo"}}`, hei)
-	iterator, err = ctx.GetStub().GetQueryResult(queryString)
-
+	records, err := getHEIMetaInfos(ctx, hei, "StudentInfo")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	if !iterator.HasNext() {
+	if len(records) == 0 {
 		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
 	}
 
-	defer iterator.Close()
-
-	for iterator.HasNext() {
-		queryRow, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
-		}
-		var record MetaInfo
-		err = json.Unmarshal(queryRow.Value, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
-		}
-
-		records = append(records, &record)
-	}
-
 	return records, nil
 }
 
@@ -906,57 +1030,42 @@ func (Transcript *SmartContract) Get_HEI_MetaInfos_StudentInfos(ctx contractapi.
 //------------------------------------------------------------------------------------------------------
 
 func (Transcript *SmartContract) Get_HEI_CourseInfos(ctx contractapi.TransactionContextInterface, hei string) ([]*CourseInfo, error) {
-	var records []*MetaInfo
-	var recordsStudentInfo []*CourseInfo
-	var err error
+	var records []*CourseInfo
 
-	records, err = Transcript.Get_HEI_MetaInfos_CourseInfos(ctx, hei)
+	entries, err := getHEIRecordEntries(ctx, hei, "CourseInfo")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	for index := 0; index < len(records); index++ {
-		recordStudentInfo, err := Transcript.Get_CourseInfo_ByHashValue(ctx, records[index].HashValue)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("failed to read from worldstate db : no record were found relevant to the given arguments on worldstate db")
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+
+		var record CourseInfo
+		err = decodeRecord(ctx, entry, &record)
 		if err != nil {
 			return nil, fmt.Errorf("%v", err)
 		}
-		recordsStudentInfo = append(recordsStudentInfo, recordStudentInfo)
+		records = append(records, &record)
 	}
-	return recordsStudentInfo, nil
+	return records, nil
 }
 
 func (Transcript *SmartContract) Get_HEI_MetaInfos_CourseInfos(ctx contractapi.TransactionContextInterface, hei string) ([]*MetaInfo, error) {
-	var iterator shim.StateQueryIteratorInterface
-	var records []*MetaInfo
-	var err error
-
-	var queryString = fmt.Sprintf(`{"selector":{"owner":"%s", "relation":"CourseInfo"}}`, hei)
-	iterator, err = ctx.GetStub().GetQueryResult(queryString)
-
+	records, err := getHEIMetaInfos(ctx, hei, "CourseInfo")
 	if err != nil {
 		return nil, fmt.Errorf("failed to read from worldstate db : %v", err)
 	}
 
-	if !iterator.HasNext() {
+	if len(records) == 0 {
 		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
 	}
 
-	defer iterator.Close()
-
-	for iterator.HasNext() {
-		queryRow, err := iterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate over the returned records : %v", err)
-		}
-		var record MetaInfo
-		err = json.Unmarshal(queryRow.Value, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch json data to struct : %v", err)
-		}
-
-		records = append(records, &record)
-	}
-
 	return records, nil
 }
 
@@ -967,31 +1076,112 @@ func (Transcript *SmartContract) Get_HEI_MetaInfos_CourseInfos(ctx contractapi.T
 // ------------------------------------------------------------------------------------------------------
 
 func (Transcript *SmartContract) GetStudentTranscript(ctx contractapi.TransactionContextInterface, hei string, studentID string) (*StudentTranscript, error) {
-	var new_transcript StudentTranscript
-	var coursesTakenbyStudent []CombinedCourseRecords
+	return Transcript.buildStudentTranscript(ctx, hei, studentID, nil)
+}
 
-	var infoStudent *StudentInfo
-	var infoCourses []*CourseInfo
-	var coursesTaken []*TakenCourse
+// buildStudentTranscript joins a student's records into a transcript; when includeCourse is not nil, only the taken courses it accepts (by hash value) are listed.
+// Records that cannot be joined are listed in the completeness report of the transcript instead of failing it.
+func (Transcript *SmartContract) buildStudentTranscript(ctx contractapi.TransactionContextInterface, hei string, studentID string, includeCourse func(hashValue string) bool) (*StudentTranscript, error) {
+	infoStudent, infoCourses, coursesTaken, err := readStudentRecords(ctx, hei, studentID)
+	if err != nil {
+		return nil, err
+	}
 
-	var err error
+	if infoStudent == nil && len(infoCourses) == 0 && len(coursesTaken) == 0 {
+		return nil, fmt.Errorf("failed to construct the transcript from the world state db: no record were found relevant to the given arguments on worldstate db")
+	}
 
-	infoStudent, err = Transcript.Get_Student_StudentInfo(ctx, hei, studentID)
+	config, err := loadHEIConfig(ctx, hei)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
 	}
 
-	infoCourses, err = Transcript.Get_Student_CourseInfos(ctx, hei, studentID)
+	scales, err := loadGradingScales(ctx, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
 	}
 
-	coursesTaken, err = Transcript.Get_Student_TakenCourses(ctx, hei, studentID)
+	return Transcript.assembleTranscript(ctx, currentState{}, hei, infoStudent, infoCourses, coursesTaken, config, scales, includeCourse)
+}
+
+// readStudentRecords reads the student's current StudentInfo record, or nil, and current CourseInfo and TakenCourse records
+func readStudentRecords(ctx contractapi.TransactionContextInterface, hei string, studentID string) (*StudentInfo, []*CourseInfo, []*TakenCourse, error) {
+	var infoStudent *StudentInfo
+	infoCourses := []*CourseInfo{}
+	coursesTaken := []*TakenCourse{}
+
+	entries, err := getStudentRecordEntries(ctx, hei, "StudentInfo", studentID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+		return nil, nil, nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	current := currentRecordEntry(entries)
+	if current != nil {
+		infoStudent = &StudentInfo{}
+		err = decodeRecord(ctx, current, infoStudent)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+		}
+	}
+
+	entries, err = getStudentRecordEntries(ctx, hei, "CourseInfo", studentID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+		var info CourseInfo
+		err = decodeRecord(ctx, entry, &info)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+		}
+		infoCourses = append(infoCourses, &info)
+	}
+
+	entries, err = getStudentRecordEntries(ctx, hei, "TakenCourse", studentID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.SupersededBy != "" {
+			continue
+		}
+		var course TakenCourse
+		err = decodeRecord(ctx, entry, &course)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+		}
+		coursesTaken = append(coursesTaken, &course)
+	}
+
+	return infoStudent, infoCourses, coursesTaken, nil
+}
+
+// assembleTranscript combines the taken courses with their course infos and offerings and computes the averages. infoStudent may be nil,
+// which the completeness report records; a taken course without a course info is reported and left out. The offerings are read from state.
+func (Transcript *SmartContract) assembleTranscript(ctx contractapi.TransactionContextInterface, state stateReader, hei string, infoStudent *StudentInfo, infoCourses []*CourseInfo,
+	coursesTaken []*TakenCourse, config *HEIConfig, scales gradeLookup, includeCourse func(hashValue string) bool) (*StudentTranscript, error) {
+
+	var new_transcript StudentTranscript
+	var err error
+	coursesTakenbyStudent := []CombinedCourseRecords{}
+	report := newCompletenessReport()
+
+	offerings := make(map[string]*CourseOffering)
+
+	catalog := make(map[string][]*CourseInfo)
+	for _, info := range infoCourses {
+		catalog[info.CourseCode] = append(catalog[info.CourseCode], info)
 	}
 
 	for _, course := range coursesTaken {
+		if includeCourse != nil && !includeCourse(course.HashValue) {
+			continue
+		}
 
 		var newCourseCombined CombinedCourseRecords
 		newCourseCombined.CourseCode = course.CourseCode
@@ -999,20 +1189,54 @@ func (Transcript *SmartContract) GetStudentTranscript(ctx contractapi.Transactio
 		newCourseCombined.Point = course.Point
 		newCourseCombined.TakenSemester = course.TakenSemester
 
-		for _, info := range infoCourses {
-			if course.CourseCode == info.CourseCode {
-				newCourseCombined.CourseName = info.CourseName
-				newCourseCombined.CourseType = info.CourseType
-				newCourseCombined.ECTS = info.ECTS
-				newCourseCombined.Credit = info.Credit
-				coursesTakenbyStudent = append(coursesTakenbyStudent, newCourseCombined)
+		if course.Term != "" {
+			offeringKey := course.CourseCode + "/" + course.Term + "/" + course.Section
+			offering, ok := offerings[offeringKey]
+			if !ok {
+				offering, err = getCourseOffering(ctx, state, hei, course.CourseCode, course.Term, course.Section)
+				if err != nil {
+					return nil, fmt.Errorf("failed to construct the transcript from the world state db: %v", err)
+				}
+				offerings[offeringKey] = offering
 			}
+
+			newCourseCombined.Term = offering.Term
+			newCourseCombined.Section = offering.Section
+			newCourseCombined.Instructor = offering.InstructorName
+			newCourseCombined.Language = offering.Language
+			newCourseCombined.DeliveryMode = offering.DeliveryMode
 		}
 
+		matches := catalog[course.CourseCode]
+		if len(matches) == 0 {
+			report.OrphanTakenCourses = append(report.OrphanTakenCourses, *course)
+			continue
+		}
+
+		if len(matches) > 1 {
+			report.addDuplicate(course.CourseCode, matches)
+		}
+
+		newCourseCombined.CourseName = matches[0].CourseName
+		newCourseCombined.CourseType = matches[0].CourseType
+		newCourseCombined.ECTS = matches[0].ECTS
+		newCourseCombined.Credit = matches[0].Credit
+		coursesTakenbyStudent = append(coursesTakenbyStudent, newCourseCombined)
+
+	}
+
+	if infoStudent != nil {
+		new_transcript.InfoStudent = *infoStudent
+	} else {
+		report.MissingStudentInfo = true
 	}
+	report.finish()
 
-	new_transcript.InfoStudent = *infoStudent
+	new_transcript.Completeness = report
 	new_transcript.Courses = coursesTakenbyStudent
+	new_transcript.Weighting = config.Weighting
+	new_transcript.Terms, new_transcript.Totals = buildTermBlocks(coursesTakenbyStudent, config, scales)
+	new_transcript.CGPA = new_transcript.Totals.GPA
 
 	return &new_transcript, nil
 
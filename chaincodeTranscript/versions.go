@@ -0,0 +1,245 @@
+package chaincodeTranscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// ------------------------------------------------------------------------------------------------------
+// *
+// * Amendable records: a new version supersedes the current one instead of being inserted next to it
+// *
+// ------------------------------------------------------------------------------------------------------
+
+// StudentInfoVersion is one version of a student's personal data together with its versioning details
+type StudentInfoVersion struct {
+	Version      int         `json:"version"`
+	Current      bool        `json:"current"`
+	SupersededBy string      `json:"superseded_by"`
+	Reason       string      `json:"reason"`
+	RecordedAt   string      `json:"recorded_at"`
+	Record       StudentInfo `json:"record"`
+}
+
+func (Transcript *SmartContract) UpdateStudentInfo(ctx contractapi.TransactionContextInterface, owner string, studentId int, faculty string, department string,
+	surname string, name string, nationalid string, registrationdate string, registrationtype string, programtype string, class int, semester int, reason string) (bool, error) {
+
+	var student StudentInfo
+
+	if reason == "" {
+		return false, fmt.Errorf("the reason of the amendment must not be empty")
+	}
+
+	records, err := getStudentMetaInfos(ctx, owner, "StudentInfo", strconv.Itoa(studentId))
+	if err != nil {
+		return false, err
+	}
+
+	current := currentMetaInfo(records)
+	if current == nil {
+		return false, fmt.Errorf("there is not a student info record of the student %d to update", studentId)
+	}
+
+	student.Faculty = faculty
+	student.Department = department
+	student.StudentID = studentId
+	student.StudentSurname = surname
+	student.StudentName = name
+	student.NationalID = nationalid
+	student.RegistrationDate, err = normalizeDate(registrationdate)
+	if err != nil {
+		return false, err
+	}
+	student.RegistrationType = registrationtype
+	student.ProgramType = programtype
+	student.Class = class
+	student.StudentSemester = semester
+
+	organization, err := loadOrganization(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	student.FacultyCode, student.DepartmentCode, err = organization.resolveStudentUnits(faculty, department)
+	if err != nil {
+		return false, err
+	}
+
+	progress, err := deriveStudentProgress(ctx, owner, &student)
+	if err != nil {
+		return false, err
+	}
+
+	student.HashValue = StructToMD5(student)
+
+	next, err := supersedeRecord(ctx, current, student.HashValue, student, reason)
+	if err != nil {
+		return false, err
+	}
+
+	if len(progress.Flags) > 0 {
+		jsonStudent, err := json.Marshal(student)
+		if err != nil {
+			return false, fmt.Errorf("failed to convert struct to json object: %v", err)
+		}
+
+		next.Flags = progress.Flags
+		err = putMetaInfo(ctx, *next, jsonStudent)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// The standing records the department of the student
+	err = Transcript.evaluateStanding(ctx, owner, strconv.Itoa(studentId), pendingRecords{infoStudent: &student})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (Transcript *SmartContract) Get_Student_StudentInfo_Versions(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*StudentInfoVersion, error) {
+	versions, err := studentInfoVersions(ctx, hei, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no record were found relevant to the given arguments on worldstate db")
+	}
+
+	return versions, nil
+}
+
+// studentInfoVersions returns the versions of a student's personal data, oldest first. Records inserted side by side before versioning
+// all count as the first version and are ordered by their hash values.
+func studentInfoVersions(ctx contractapi.TransactionContextInterface, hei string, studentID string) ([]*StudentInfoVersion, error) {
+	var versions []*StudentInfoVersion
+
+	entries, err := getStudentRecordEntries(ctx, hei, "StudentInfo", studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := currentRecordEntry(entries)
+
+	for _, entry := range entries {
+		var version StudentInfoVersion
+		err = decodeRecord(ctx, entry, &version.Record)
+		if err != nil {
+			return nil, fmt.Errorf("error during fetch student info record by hash value: %v", err)
+		}
+
+		version.Version = versionOf(&entry.MetaInfo)
+		version.Current = entry == current
+		version.SupersededBy = entry.SupersededBy
+		version.Reason = entry.Reason
+		version.RecordedAt = entry.RecordedAt
+
+		versions = append(versions, &version)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].Version != versions[j].Version {
+			return versions[i].Version < versions[j].Version
+		}
+		return versions[i].Record.HashValue < versions[j].Record.HashValue
+	})
+
+	return versions, nil
+}
+
+// versionOf reads the version number of a MetaInfo record; records written before versioning count as the first version
+func versionOf(meta *MetaInfo) int {
+	if meta.Version < 1 {
+		return 1
+	}
+	return meta.Version
+}
+
+// currentMetaInfo picks the current version among the MetaInfo records of one student and relation. Records
+// inserted side by side before versioning are all unsuperseded, in which case the last one wins as it always did.
+func currentMetaInfo(records []*MetaInfo) *MetaInfo {
+	var current *MetaInfo
+
+	for _, record := range records {
+		if record.SupersededBy != "" {
+			continue
+		}
+		if current == nil || versionOf(record) >= versionOf(current) {
+			current = record
+		}
+	}
+
+	return current
+}
+
+// supersedeRecord stores record under newHash as the next version of the record indexed by current, marks current as superseded and
+// returns the MetaInfo record of the new version. The MetaInfo records are keyed by the hash of the record, so returning to the data of
+// an older version would overwrite that version; such an amendment is refused.
+func supersedeRecord(ctx contractapi.TransactionContextInterface, current *MetaInfo, newHash string, record interface{}, reason string) (*MetaInfo, error) {
+	if newHash == current.HashValue {
+		return nil, fmt.Errorf("the record you sent is identical to the current version")
+	}
+
+	versions, err := getStudentMetaInfos(ctx, current.Owner, current.Relation, current.StudentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		if version.HashValue == newHash {
+			return nil, fmt.Errorf("the record you sent is identical to the version %d", versionOf(version))
+		}
+	}
+
+	recordedAt, err := txTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonRecord, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert struct to json object: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(newHash, jsonRecord)
+	if err != nil {
+		return nil, fmt.Errorf("failed to put %s record to world state. %v", current.Relation, err)
+	}
+
+	next := MetaInfo{Owner: current.Owner, StudentID: current.StudentID, Relation: current.Relation, HashValue: newHash,
+		Version: versionOf(current) + 1, Reason: reason, RecordedAt: recordedAt}
+
+	previous := *current
+	previous.Version = versionOf(current)
+	previous.SupersededBy = newHash
+
+	err = updateMetaInfo(ctx, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	err = putMetaInfo(ctx, next, jsonRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	return &next, nil
+}
+
+// txTimestamp returns the timestamp of the running transaction in RFC3339, which is identical on every endorsing peer
+func txTimestamp(ctx contractapi.TransactionContextInterface) (string, error) {
+	timestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return "", fmt.Errorf("failed to read the transaction timestamp: %v", err)
+	}
+
+	return timestamp.AsTime().UTC().Format(time.RFC3339), nil
+}
@@ -0,0 +1,116 @@
+package chaincodeTranscript
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Amending a record back to the data of an older version is refused, which leaves every version listed as it was
+func TestUpdateStudentInfoRefusesARevertToAnOlderVersion(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+
+	update := func(name string) error {
+		return ledger.updateStudentInfo(190908809, "Department of Computer Engineering", name, 0, "Corrected the name")
+	}
+
+	for _, name := range []string{"Ayse", "Fatma"} {
+		if err := update(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := update("Ayse"); err == nil {
+		t.Error("reverting to the data of version 2 succeeded")
+	}
+
+	versions, err := ledger.contract.Get_Student_StudentInfo_Versions(ledger.ctx(), testHEI, "190908809")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type listed struct {
+		Version      int
+		Current      bool
+		SupersededBy bool
+		Name         string
+	}
+	var got []listed
+	for _, version := range versions {
+		got = append(got, listed{version.Version, version.Current, version.SupersededBy != "", version.Record.StudentName})
+	}
+
+	want := []listed{{1, false, true, "Test"}, {2, false, true, "Ayse"}, {3, true, false, "Fatma"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got versions %+v, want %+v", got, want)
+	}
+}
+
+// An amendment derives a class or semester of 0 and flags a given one that disagrees with the derived one, as the insert does
+func TestUpdateStudentInfoDerivesTheClass(t *testing.T) {
+	tests := []struct {
+		name      string
+		class     int
+		wantClass int
+		wantFlags int
+	}{
+		{name: "class left to the chaincode", class: 0, wantClass: 1, wantFlags: 0},
+		{name: "class that agrees", class: 1, wantClass: 1, wantFlags: 0},
+		{name: "class that disagrees", class: 3, wantClass: 3, wantFlags: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger := newTestLedger(t)
+			ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+
+			err := ledger.updateStudentInfo(190908809, "Department of Computer Engineering", "Ayse", test.class, "Corrected the name")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			records, err := getStudentMetaInfos(ledger.ctx(), testHEI, "StudentInfo", "190908809")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			current := currentMetaInfo(records)
+			var student StudentInfo
+			err = getRecordByHashValue(ledger.ctx(), current.HashValue, &student)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if student.Class != test.wantClass || len(current.Flags) != test.wantFlags {
+				t.Errorf("got the class %d and the flags %v, want %d and %d flags", student.Class, current.Flags, test.wantClass, test.wantFlags)
+			}
+		})
+	}
+}
+
+// The pages of the versions list them oldest first across the pages, not only within each page
+func TestGetStudentStudentInfoVersionsPaginatedOrder(t *testing.T) {
+	ledger := newTestLedger(t)
+	ledger.addStudent(190908809, "Selvi", "Department of Computer Engineering", "2022-09-02")
+	for _, name := range []string{"Ayse", "Fatma", "Zeynep", "Elif"} {
+		if err := ledger.updateStudentInfo(190908809, "Department of Computer Engineering", name, 0, "Corrected the name"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions := allPages(t, func(bookmark string) ([]*StudentInfoVersion, string, error) {
+		page, err := ledger.contract.Get_Student_StudentInfo_Versions_Paginated(ledger.ctx(), testHEI, "190908809", 2, bookmark)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Records, page.Bookmark, nil
+	})
+
+	var got []int
+	for _, version := range versions {
+		got = append(got, version.Version)
+	}
+
+	if want := []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got the versions %v, want %v", got, want)
+	}
+}